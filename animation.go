@@ -0,0 +1,170 @@
+package sysgapp
+
+import "log"
+
+// SPRITE ANIMATION
+// SpriteAnimation plays a list of frames over time, so a caller can Update(DeltaTime()) each frame
+// and then draw with DrawSpriteAnimationTinted. This is not on SpriteInstance because its fields
+// are declared outside this file set with no room for a clock, and keeping playback in a table
+// keyed by instance would hold every instance ever animated alive. Both types draw their frame
+// through the same helpers, so they look identical on screen.
+type SpriteAnimation struct {
+	frames        []SpriteFrame
+	frameDuration float32
+	loop          bool
+	elapsed       float32
+	current       int
+	paused        bool
+	finished      bool
+	onComplete    func()
+}
+
+func NewSpriteAnimation(frames []SpriteFrame, frameDuration float32, loop bool) *SpriteAnimation {
+	return &SpriteAnimation{
+		frames:        frames,
+		frameDuration: frameDuration,
+		loop:          loop,
+	}
+}
+
+// Update advances the animation by dt seconds, wrapping when looping or holding the last frame otherwise
+func (a *SpriteAnimation) Update(dt float32) {
+	if a.paused || a.finished || len(a.frames) == 0 || a.frameDuration <= 0 {
+		return
+	}
+	a.elapsed += dt
+	for a.elapsed >= a.frameDuration {
+		a.elapsed -= a.frameDuration
+		if a.current < len(a.frames)-1 {
+			a.current++
+			continue
+		}
+		if a.loop {
+			a.current = 0
+			continue
+		}
+		a.elapsed = 0
+		a.finished = true
+		if a.onComplete != nil {
+			a.onComplete()
+		}
+		return
+	}
+}
+func (a *SpriteAnimation) Reset() {
+	a.elapsed = 0
+	a.current = 0
+	a.finished = false
+}
+func (a *SpriteAnimation) Pause() {
+	a.paused = true
+}
+func (a *SpriteAnimation) Play() {
+	a.paused = false
+}
+func (a *SpriteAnimation) IsFinished() bool {
+	return a.finished
+}
+
+// SetOnComplete sets a callback fired when a non-looping animation reaches the end of its last frame
+func (a *SpriteAnimation) SetOnComplete(op func()) {
+	a.onComplete = op
+}
+
+// GetFrame returns the frame to draw, or nil for an animation with no frames
+func (a *SpriteAnimation) GetFrame() *SpriteFrame {
+	if len(a.frames) == 0 {
+		return nil
+	}
+	return &a.frames[a.current]
+}
+
+// ANIMATION CONTROLLER
+// AnimationController switches between named animations, like idle, walk, and attack, returning
+// to its default animation whenever a non-looping one finishes
+type AnimationController struct {
+	animations  map[string]*SpriteAnimation
+	current     string
+	defaultName string
+}
+
+func NewAnimationController() *AnimationController {
+	return &AnimationController{animations: make(map[string]*SpriteAnimation)}
+}
+
+// AddAnimation adds or replaces the animation called name, played at fps frames per second. The
+// first animation added becomes both the default and the one playing. An fps of zero or less
+// would never advance, leaving a one-shot playing forever, so it is logged and nil is returned.
+func (c *AnimationController) AddAnimation(name string, frames []SpriteFrame, fps float32, loop bool) *SpriteAnimation {
+	if fps <= 0 {
+		log.Printf("sysgapp: animation %q needs a positive fps, got %v, not added", name, fps)
+		return nil
+	}
+	anim := NewSpriteAnimation(frames, 1/fps, loop)
+	c.animations[name] = anim
+	if c.defaultName == "" {
+		c.defaultName = name
+	}
+	if c.current == "" {
+		c.current = name
+	}
+	return anim
+}
+
+// SetDefault sets the animation returned to when a non-looping animation finishes
+func (c *AnimationController) SetDefault(name string) {
+	if _, ok := c.animations[name]; !ok {
+		log.Printf("sysgapp: animation controller has no animation %q, default unchanged", name)
+		return
+	}
+	c.defaultName = name
+}
+
+// Play switches to the animation called name from its first frame. Playing the animation that is
+// already running keeps its place, so Play can be called every frame while a key is held.
+func (c *AnimationController) Play(name string) {
+	anim, ok := c.animations[name]
+	if !ok {
+		log.Printf("sysgapp: animation controller has no animation %q, keeping %q", name, c.current)
+		return
+	}
+	if name == c.current && !anim.IsFinished() {
+		return
+	}
+	c.current = name
+	anim.Reset()
+	anim.Play()
+}
+
+// Update advances the playing animation by dt seconds, typically DeltaTime()
+func (c *AnimationController) Update(dt float32) {
+	anim, ok := c.animations[c.current]
+	if !ok {
+		return
+	}
+	anim.Update(dt)
+	if anim.IsFinished() && c.defaultName != c.current {
+		c.Play(c.defaultName)
+	}
+}
+
+// Current returns the name of the playing animation
+func (c *AnimationController) Current() string {
+	return c.current
+}
+
+// Animation returns the animation called name, to pause it or set its completion callback
+func (c *AnimationController) Animation(name string) (*SpriteAnimation, bool) {
+	anim, ok := c.animations[name]
+	return anim, ok
+}
+
+// CurrentFrame returns the frame to draw, or the zero frame if nothing with frames is playing
+func (c *AnimationController) CurrentFrame() SpriteFrame {
+	if anim, ok := c.animations[c.current]; ok {
+		if frame := anim.GetFrame(); frame != nil {
+			return *frame
+		}
+	}
+	return SpriteFrame{}
+}
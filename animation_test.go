@@ -0,0 +1,198 @@
+package sysgapp
+
+import "testing"
+
+func TestSpriteAnimationWithoutFrames(t *testing.T) {
+	s, lib := newFakeSolution()
+	anim := NewSpriteAnimation(nil, 0.1, true)
+	anim.Update(1)
+	if frame := anim.GetFrame(); frame != nil {
+		t.Fatalf("GetFrame = %v, want nil", frame)
+	}
+	s.DrawSpriteAnimationTinted(anim, Vec2{}, &ColorWhite)
+	s.DrawSpriteAnimationDestRectTinted(anim, NewRect2D(Vec2{}, Vec2{8, 8}), &ColorWhite)
+	if n := lib.BatchVertexCount(); n != 0 {
+		t.Errorf("drawing an animation without frames added %d vertices", n)
+	}
+}
+func TestAnimationController(t *testing.T) {
+	frames := func(tex TextureIndex, n int) []SpriteFrame {
+		out := make([]SpriteFrame, n)
+		for i := range out {
+			out[i] = SpriteFrame{texIndex: tex, texRect: NewRect2D(Vec2{float32(i * 8), 0}, Vec2{8, 8})}
+		}
+		return out
+	}
+	ctrl := NewAnimationController()
+	ctrl.AddAnimation("idle", frames(1, 2), 10, true)
+	ctrl.AddAnimation("attack", frames(2, 3), 10, false)
+	if ctrl.Current() != "idle" {
+		t.Fatalf("playing %q, want the first animation added", ctrl.Current())
+	}
+	ctrl.Update(0.15)
+	if f := ctrl.CurrentFrame(); f != frames(1, 2)[1] {
+		t.Errorf("idle frame after 0.15s is %v, want the second", f)
+	}
+	// Playing the running animation keeps its place
+	ctrl.Play("idle")
+	if f := ctrl.CurrentFrame(); f != frames(1, 2)[1] {
+		t.Errorf("Play on the running animation restarted it at %v", f)
+	}
+	ctrl.Play("missing")
+	if ctrl.Current() != "idle" {
+		t.Errorf("playing an unknown animation switched to %q", ctrl.Current())
+	}
+	ctrl.Play("attack")
+	if f := ctrl.CurrentFrame(); f != frames(2, 3)[0] {
+		t.Errorf("attack starts at %v, want its first frame", f)
+	}
+	ctrl.Update(0.25)
+	if ctrl.Current() != "attack" {
+		t.Fatalf("attack ended early, now playing %q", ctrl.Current())
+	}
+	ctrl.Update(0.1)
+	if ctrl.Current() != "idle" {
+		t.Errorf("finished one-shot left %q playing, want the default", ctrl.Current())
+	}
+	if anim, _ := ctrl.Animation("idle"); anim.current != 0 {
+		t.Errorf("returning to idle resumed at frame %d, want 0", anim.current)
+	}
+}
+func TestAnimationControllerRejectsNonPositiveFPS(t *testing.T) {
+	ctrl := NewAnimationController()
+	for _, fps := range []float32{0, -5} {
+		if anim := ctrl.AddAnimation("still", []SpriteFrame{{texIndex: 1}}, fps, false); anim != nil {
+			t.Errorf("fps %v added an animation", fps)
+		}
+	}
+	if _, ok := ctrl.Animation("still"); ok || ctrl.Current() != "" {
+		t.Errorf("rejected animation is registered, playing %q", ctrl.Current())
+	}
+	if f := ctrl.CurrentFrame(); f != (SpriteFrame{}) {
+		t.Errorf("CurrentFrame with nothing playing is %v, want the zero frame", f)
+	}
+	s, lib := newFakeSolution()
+	s.DrawAnimationControllerTinted(ctrl, Vec2{}, &ColorWhite)
+	if n := lib.BatchVertexCount(); n != 0 {
+		t.Errorf("drawing an empty controller added %d vertices", n)
+	}
+}
+func TestSpriteAnimationPlayback(t *testing.T) {
+	frames := []SpriteFrame{{texIndex: 1}, {texIndex: 2}, {texIndex: 3}}
+	at := func(anim *SpriteAnimation) TextureIndex { return anim.GetFrame().texIndex }
+	t.Run("advances and loops", func(t *testing.T) {
+		anim := NewSpriteAnimation(frames, 0.1, true)
+		completions := 0
+		anim.SetOnComplete(func() { completions++ })
+		steps := []TextureIndex{1, 2, 3, 1, 2}
+		for i, want := range steps {
+			if got := at(anim); got != want {
+				t.Fatalf("step %d: frame %d, want %d", i, got, want)
+			}
+			anim.Update(0.1)
+		}
+		anim.Update(0.65)
+		if got := at(anim); got != 3 {
+			t.Errorf("after wrapping several times in one update at frame %d, want 3", got)
+		}
+		if anim.IsFinished() || completions != 0 {
+			t.Errorf("looping animation finished %v with %d completions, want never", anim.IsFinished(), completions)
+		}
+	})
+	t.Run("stops on the last frame", func(t *testing.T) {
+		anim := NewSpriteAnimation(frames, 0.1, false)
+		completions := 0
+		anim.SetOnComplete(func() { completions++ })
+		anim.Update(0.25)
+		if got := at(anim); got != 3 || anim.IsFinished() {
+			t.Fatalf("at frame %d finished %v, want 3 and still playing", got, anim.IsFinished())
+		}
+		anim.Update(0.1)
+		if !anim.IsFinished() || completions != 1 {
+			t.Fatalf("finished %v with %d completions, want true and 1", anim.IsFinished(), completions)
+		}
+		anim.Update(1)
+		if got := at(anim); got != 3 || completions != 1 {
+			t.Errorf("after finishing at frame %d with %d completions, want 3 and 1", got, completions)
+		}
+		anim.Reset()
+		if got := at(anim); got != 1 || anim.IsFinished() {
+			t.Errorf("Reset left frame %d finished %v, want 1 and playing", got, anim.IsFinished())
+		}
+	})
+	t.Run("pauses", func(t *testing.T) {
+		anim := NewSpriteAnimation(frames, 0.1, true)
+		anim.Pause()
+		anim.Update(0.15)
+		if got := at(anim); got != 1 {
+			t.Errorf("paused animation moved to frame %d", got)
+		}
+		anim.Play()
+		anim.Update(0.15)
+		if got := at(anim); got != 2 {
+			t.Errorf("resumed animation at frame %d, want 2", got)
+		}
+	})
+}
+func TestSpriteAnimationDrawsCurrentFrame(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.texSizes[1] = Vec2{64, 64}
+	anim := NewSpriteAnimation([]SpriteFrame{
+		{texIndex: 1, texRect: NewRect2D(Vec2{0, 0}, Vec2{16, 16})},
+		{texIndex: 1, texRect: NewRect2D(Vec2{16, 0}, Vec2{16, 16}), drawOffset: Vec2{-8, -16}},
+	}, 0.1, true)
+	anim.Update(0.1)
+	s.DrawSpriteAnimationTinted(anim, Vec2{100, 100}, &ColorWhite)
+	if len(lib.vertices) != 4 {
+		t.Fatalf("drew %d vertices, want one quad", len(lib.vertices))
+	}
+	// The second frame, 8 left and 16 up from pos by its draw offset
+	lo, hi := lib.vertices[0].pos, lib.vertices[0].pos
+	for _, v := range lib.vertices {
+		lo = Vec2{fmin(lo.X(), v.pos.X()), fmin(lo.Y(), v.pos.Y())}
+		hi = Vec2{fmax(hi.X(), v.pos.X()), fmax(hi.Y(), v.pos.Y())}
+	}
+	if lo != (Vec2{92, 84}) || hi != (Vec2{108, 100}) {
+		t.Errorf("quad spans %v to %v, want {92 84} to {108 100}", lo, hi)
+	}
+}
+func TestSpriteFrameNinePatch(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.texSizes[1] = Vec2{64, 64}
+	bordered := SpriteFrame{texIndex: 1, texRect: NewRect2D(Vec2{0, 0}, Vec2{16, 16}), drawOffset: Vec2{4, 4}}
+	plain := SpriteFrame{texIndex: 1, texRect: NewRect2D(Vec2{16, 0}, Vec2{16, 16}), drawOffset: Vec2{4, 4}}
+	s.SetSpriteFrameNinePatch(bordered, Rect2D{4, 4, 4, 4})
+	if insets, ok := s.SpriteFrameNinePatch(bordered); !ok || insets != (Rect2D{4, 4, 4, 4}) {
+		t.Fatalf("SpriteFrameNinePatch = %v, %v", insets, ok)
+	}
+	if _, ok := s.SpriteFrameNinePatch(plain); ok {
+		t.Fatal("a frame without a border reports one")
+	}
+	bounds := func() (lo, hi Vec2) {
+		lo, hi = lib.vertices[0].pos, lib.vertices[0].pos
+		for _, v := range lib.vertices {
+			lo = Vec2{fmin(lo.X(), v.pos.X()), fmin(lo.Y(), v.pos.Y())}
+			hi = Vec2{fmax(hi.X(), v.pos.X()), fmax(hi.Y(), v.pos.Y())}
+		}
+		return lo, hi
+	}
+	dest := NewRect2D(Vec2{0, 0}, Vec2{32, 32})
+	for _, tt := range []struct {
+		name  string
+		frame SpriteFrame
+		quads int
+	}{
+		{"bordered", bordered, 9},
+		{"plain", plain, 1},
+	} {
+		s.drawSpriteFrameNinePatch(&tt.frame, dest, &ColorWhite)
+		if n := len(lib.vertices) / 4; n != tt.quads {
+			t.Errorf("%s: drew %d quads, want %d", tt.name, n, tt.quads)
+		}
+		// Doubling the size doubles the draw offset, as in the dest rect draw
+		if lo, hi := bounds(); lo != (Vec2{8, 8}) || hi != (Vec2{40, 40}) {
+			t.Errorf("%s: drew from %v to %v, want {8 8} to {40 40}", tt.name, lo, hi)
+		}
+		s.FlushBatch()
+	}
+}
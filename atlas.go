@@ -0,0 +1,115 @@
+package sysgapp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+
+	V "github.com/gabe-lee/genvecs"
+)
+
+var ErrAtlasFull = errors.New("sysgapp: texture atlas has no room for region")
+
+// TEXTURE ATLAS
+type TextureAtlas struct {
+	texIndex TextureIndex
+	size     Vec2
+	padding  float32
+	cursor   Vec2
+	shelfH   float32
+	regions  []Rect2D
+	pixels   []byte
+}
+
+func NewTextureAtlas(size Vec2) *TextureAtlas {
+	return &TextureAtlas{
+		size:    size,
+		padding: 1,
+		regions: make([]Rect2D, 0, 64),
+	}
+}
+
+func (a *TextureAtlas) TextureIndex() TextureIndex {
+	return a.texIndex
+}
+
+// SetTextureIndex sets the index returned by Add, UploadTextureAtlas sets it as well
+func (a *TextureAtlas) SetTextureIndex(texIndex TextureIndex) {
+	a.texIndex = texIndex
+}
+func (a *TextureAtlas) Size() Vec2 {
+	return a.size
+}
+func (a *TextureAtlas) Region(index int) Rect2D {
+	return a.regions[index]
+}
+func (a *TextureAtlas) RegionCount() int {
+	return len(a.regions)
+}
+
+// Reserve places a region of the given size using shelf packing and returns its index
+func (a *TextureAtlas) Reserve(size Vec2) (index int, rect Rect2D, err error) {
+	w, h := size.X()+a.padding, size.Y()+a.padding
+	if w > a.size.X() || h > a.size.Y() {
+		return -1, Rect2D{}, ErrAtlasFull
+	}
+	if a.cursor.X()+w > a.size.X() {
+		a.cursor = Vec2{0, a.cursor.Y() + a.shelfH}
+		a.shelfH = 0
+	}
+	if a.cursor.Y()+h > a.size.Y() {
+		return -1, Rect2D{}, ErrAtlasFull
+	}
+	rect = NewRect2D(a.cursor, size)
+	a.regions = append(a.regions, rect)
+	a.cursor = Vec2{a.cursor.X() + w, a.cursor.Y()}
+	if h > a.shelfH {
+		a.shelfH = h
+	}
+	return len(a.regions) - 1, rect, nil
+}
+
+// Add decodes a PNG or JPEG image, packs it with Reserve, and copies its pixels into the atlas,
+// returning the atlas texture index and the image's source rect. A full atlas returns ErrAtlasFull
+// and leaves the atlas pixels untouched. Upload the atlas with UploadTextureAtlas once images are added.
+func (a *TextureAtlas) Add(data []byte, imgType ImageType) (TextureIndex, Rect2D, error) {
+	if imgType != PNG && imgType != JPEG {
+		return a.texIndex, Rect2D{}, fmt.Errorf("sysgapp: texture atlas can only decode PNG and JPEG images, not type %d", imgType)
+	}
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return a.texIndex, Rect2D{}, fmt.Errorf("sysgapp: decoding atlas image: %w", err)
+	}
+	bounds := src.Bounds()
+	_, rect, err := a.Reserve(Vec2{float32(bounds.Dx()), float32(bounds.Dy())})
+	if err != nil {
+		return a.texIndex, Rect2D{}, err
+	}
+	dst := a.image()
+	at := image.Pt(int(rect.X()), int(rect.Y()))
+	draw.Draw(dst, image.Rectangle{Min: at, Max: at.Add(bounds.Size())}, src, bounds.Min, draw.Src)
+	return a.texIndex, rect, nil
+}
+
+// image wraps the atlas pixels, allocating them transparent on first use
+func (a *TextureAtlas) image() *image.RGBA {
+	w, h := int(a.size.X()), int(a.size.Y())
+	if a.pixels == nil {
+		a.pixels = make([]byte, w*h*4)
+	}
+	return &image.RGBA{Pix: a.pixels, Stride: w * 4, Rect: image.Rect(0, 0, w, h)}
+}
+
+// Texture returns the atlas pixels as a RawRGBA texture, sharing rather than copying them
+func (a *TextureAtlas) Texture() *Texture {
+	a.image()
+	return NewTexture(a.pixels, RawRGBA, V.F32Vec2{a.size.X(), a.size.Y()}, 0)
+}
+
+// UploadTextureAtlas adds the atlas pixels as texture texIndex, call it again after adding more images to update it
+func (s *SystemSolution) UploadTextureAtlas(texIndex TextureIndex, atlas *TextureAtlas) {
+	atlas.texIndex = texIndex
+	s.AddTexture(texIndex, atlas.Texture())
+}
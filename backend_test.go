@@ -0,0 +1,141 @@
+package sysgapp
+
+// fakeBackend is a GraphicsInterface that keeps the batch in memory and counts flushes
+// instead of drawing, so tests can check what SystemSolution submits
+type fakeBackend struct {
+	windowSize Vec2
+	vertices   []fakeVertex
+	indexes    []uint32
+	flushes    int          // Non-empty batch flushes
+	maxBatch   int          // Most vertices in any flushed batch
+	lastBatch  int          // Vertices in the most recent non-empty flush
+	flushed    []fakeVertex // Copy of the most recent non-empty flush
+	badIndexes int          // Indexes past the end of their batch when it was flushed
+	primitives int
+	premult    bool
+	blend      BlendMode
+	texture    TextureIndex
+	bound      drawTarget // Target last bound or restored
+}
+type fakeVertex struct {
+	pos   Vec2
+	color Color
+	uv    Vec2
+}
+
+var _ GraphicsInterface = (*fakeBackend)(nil)
+
+func newFakeSolution() (*SystemSolution, *fakeBackend) {
+	lib := &fakeBackend{windowSize: Vec2{800, 600}}
+	return NewSystemSolution(lib), lib
+}
+func (f *fakeBackend) Init()         {}
+func (f *fakeBackend) Run(op func()) { op() }
+func (f *fakeBackend) Teardown()     {}
+func (f *fakeBackend) GetWindowSize() Vec2 {
+	return f.windowSize
+}
+func (f *fakeBackend) SetVSync(enabled bool) {}
+func (f *fakeBackend) IsVSyncEnabled() bool  { return false }
+func (f *fakeBackend) AddRenderPipeChecked(rendIndex RenderIndex, vShader *Shader, fShader *Shader) error {
+	return nil
+}
+func (f *fakeBackend) SetSharedUniformMatrix(name string, m [16]float32)                         {}
+func (f *fakeBackend) AddTexture(texIndex TextureIndex, texture *Texture)                        {}
+func (f *fakeBackend) AddRenderSurface(surfIndex SurfaceIndex, texIndex TextureIndex, size Vec2) {}
+func (f *fakeBackend) AddRenderSurfaceMRT(surfIndex SurfaceIndex, texIndexes []TextureIndex, size Vec2) {
+}
+func (f *fakeBackend) ClearSurface(baseColor *Color)                                          {}
+func (f *fakeBackend) ClearSurfaceArea(surfIndex SurfaceIndex, baseColor *Color, rect Rect2D) {}
+func (f *fakeBackend) ReadSurfacePixels(surfIndex SurfaceIndex) ([]byte, Vec2, error) {
+	return nil, Vec2{}, nil
+}
+func (f *fakeBackend) DrawBatchIndexedTriangles2D() {
+	if len(f.indexes) > 0 {
+		f.flushes++
+		f.lastBatch = len(f.vertices)
+		f.flushed = append(f.flushed[:0], f.vertices...)
+		if f.lastBatch > f.maxBatch {
+			f.maxBatch = f.lastBatch
+		}
+		for _, idx := range f.indexes {
+			if int(idx) >= len(f.vertices) {
+				f.badIndexes++
+			}
+		}
+	}
+	f.vertices = f.vertices[:0]
+	f.indexes = f.indexes[:0]
+}
+func (f *fakeBackend) AddVertexToBatch(pos Vec2, color *Color, uv Vec2) (index uint16) {
+	return uint16(f.AddVertexToBatch32(pos, color, uv))
+}
+func (f *fakeBackend) AddIndexesToBatch(indexes ...uint16) {
+	for _, idx := range indexes {
+		f.indexes = append(f.indexes, uint32(idx))
+	}
+}
+func (f *fakeBackend) SetIndexWidth(width int) {}
+func (f *fakeBackend) AddVertexToBatch32(pos Vec2, color *Color, uv Vec2) (index uint32) {
+	f.vertices = append(f.vertices, fakeVertex{pos: pos, color: *color, uv: uv})
+	return uint32(len(f.vertices) - 1)
+}
+func (f *fakeBackend) AddIndexesToBatch32(indexes ...uint32) {
+	f.indexes = append(f.indexes, indexes...)
+}
+func (f *fakeBackend) BatchVertexCount() int                 { return len(f.vertices) }
+func (f *fakeBackend) BatchIndexCount() int                  { return len(f.indexes) }
+func (f *fakeBackend) SetAlphaPremultiplied(premult bool)    { f.premult = premult }
+func (f *fakeBackend) SetBlendMode(mode BlendMode)           { f.blend = mode }
+func (f *fakeBackend) SetBatchTexture(texIndex TextureIndex) { f.texture = texIndex }
+func (f *fakeBackend) DrawPrimitiveVertexArray2D(verts []Vec2, color *Color, mode VertexMode) {
+	f.primitives++
+}
+func (f *fakeBackend) DrawToScreen(op func()) {
+	f.bound = drawTarget{screen: true}
+	op()
+}
+func (f *fakeBackend) DrawToSurface(surfIndex SurfaceIndex, op func()) {
+	f.bound = drawTarget{surfIndex: surfIndex}
+	op()
+}
+func (f *fakeBackend) DrawUsingRenderPipe(rendIndex RenderIndex, op func()) { op() }
+func (f *fakeBackend) RestoreScreen()                                       { f.bound = drawTarget{screen: true} }
+func (f *fakeBackend) RestoreSurface(surfIndex SurfaceIndex) {
+	f.bound = drawTarget{surfIndex: surfIndex}
+}
+func (f *fakeBackend) BlitSurface(src SurfaceIndex, dst SurfaceIndex, srcRect Rect2D, dstRect Rect2D, color *Color) error {
+	return nil
+}
+func (f *fakeBackend) PushClipRect(rect Rect2D)               {}
+func (f *fakeBackend) PopClipRect()                           {}
+func (f *fakeBackend) ClearStencil()                          {}
+func (f *fakeBackend) BeginStencilWrite(level uint8)          {}
+func (f *fakeBackend) BeginStencilErase(level uint8)          {}
+func (f *fakeBackend) BeginStencilTest(level uint8)           {}
+func (f *fakeBackend) EndStencil()                            {}
+func (f *fakeBackend) SetClipboardText(text string)           {}
+func (f *fakeBackend) GetClipboardText() string               { return "" }
+func (f *fakeBackend) GetMousePosition() Vec2                 { return Vec2{} }
+func (f *fakeBackend) SetCursorVisible(visible bool)          {}
+func (f *fakeBackend) SetCursorMode(mode CursorMode)          {}
+func (f *fakeBackend) GetModifierState() KeyboardMod          { return 0 }
+func (f *fakeBackend) GetActiveTouches() []Touch              { return nil }
+func (f *fakeBackend) SetCallbackOnRuneInput(op func(r rune)) {}
+func (f *fakeBackend) GetMouseButtonState(button MouseButton) InputState {
+	return Released
+}
+func (f *fakeBackend) GetKeyboardKeyState(key KeyboardKey) InputState {
+	return Released
+}
+func (f *fakeBackend) SetCustomCursor(tex *Texture, hotspot Vec2)         {}
+func (f *fakeBackend) SetCallbackOnMouseWheelScroll(op func(offset Vec2)) {}
+func (f *fakeBackend) SetCallbackOnMouseMove(op func(pos Vec2))           {}
+func (f *fakeBackend) SetCallbackOnMouseButton(op func(button MouseButton, state InputState)) {
+}
+func (f *fakeBackend) SetCallbackOnKeyPress(op func(key KeyboardKey, state InputState, mods KeyboardMod)) {
+}
+func (f *fakeBackend) SetCallbackOnTouchBegin(op func(touch Touch))    {}
+func (f *fakeBackend) SetCallbackOnTouchMove(op func(touch Touch))     {}
+func (f *fakeBackend) SetCallbackOnTouchEnd(op func(touch Touch))      {}
+func (f *fakeBackend) SetCallbackOnWindowResize(op func(newSize Vec2)) {}
@@ -0,0 +1,133 @@
+package sysgapp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func clampChannel(v float32) float32 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+func (c Color) clamped() Color {
+	return Color{clampChannel(c[0]), clampChannel(c[1]), clampChannel(c[2]), clampChannel(c[3])}
+}
+
+// Lerp blends from c (t = 0) to other (t = 1), t is clamped to that range
+func (c Color) Lerp(other Color, t float32) Color {
+	t = clampChannel(t)
+	return Color{
+		c[0] + (other[0]-c[0])*t,
+		c[1] + (other[1]-c[1])*t,
+		c[2] + (other[2]-c[2])*t,
+		c[3] + (other[3]-c[3])*t,
+	}.clamped()
+}
+func (c Color) WithAlpha(a float32) Color {
+	return Color{c[0], c[1], c[2], a}.clamped()
+}
+func (c Color) Multiply(other Color) Color {
+	return Color{c[0] * other[0], c[1] * other[1], c[2] * other[2], c[3] * other[3]}.clamped()
+}
+func (c Color) Premultiply() Color {
+	return Color{c[0] * c[3], c[1] * c[3], c[2] * c[3], c[3]}.clamped()
+}
+
+// ColorFromHex parses #RGB, #RGBA, #RRGGBB, or #RRGGBBAA (the # is optional), alpha defaults to opaque
+func ColorFromHex(hex string) (Color, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	switch len(digits) {
+	case 3, 4:
+		expanded := make([]byte, 0, 8)
+		for i := 0; i < len(digits); i++ {
+			expanded = append(expanded, digits[i], digits[i])
+		}
+		digits = string(expanded)
+	case 6, 8:
+	default:
+		return Color{}, fmt.Errorf("sysgapp: hex color %q has %d digits, expected 3, 4, 6, or 8", hex, len(digits))
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	var c Color
+	for i := 0; i < 4; i++ {
+		v, err := strconv.ParseUint(digits[i*2:i*2+2], 16, 8)
+		if err != nil {
+			return Color{}, fmt.Errorf("sysgapp: hex color %q contains invalid digits %q", hex, digits[i*2:i*2+2])
+		}
+		c[i] = float32(v) / 255
+	}
+	return c, nil
+}
+
+// ToHex formats the color as #RRGGBBAA
+func (c Color) ToHex() string {
+	c = c.clamped()
+	return fmt.Sprintf("#%02X%02X%02X%02X", toByte(c[0]), toByte(c[1]), toByte(c[2]), toByte(c[3]))
+}
+func toByte(v float32) uint8 {
+	return uint8(math.Round(float64(v) * 255))
+}
+
+// ColorFromHSV builds a color from hue in degrees (wrapped to 0..360) and saturation, value, and alpha in 0..1
+func ColorFromHSV(h float32, s float32, v float32, a float32) Color {
+	hue := math.Mod(float64(h), 360)
+	if hue < 0 {
+		hue += 360
+	}
+	s, v = clampChannel(s), clampChannel(v)
+	chroma := float64(v * s)
+	x := chroma * (1 - math.Abs(math.Mod(hue/60, 2)-1))
+	m := float64(v) - chroma
+	var r, g, b float64
+	switch {
+	case hue < 60:
+		r, g, b = chroma, x, 0
+	case hue < 120:
+		r, g, b = x, chroma, 0
+	case hue < 180:
+		r, g, b = 0, chroma, x
+	case hue < 240:
+		r, g, b = 0, x, chroma
+	case hue < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return Color{float32(r + m), float32(g + m), float32(b + m), a}.clamped()
+}
+
+// ToHSV returns hue in degrees (0..360) and saturation, value, and alpha in 0..1
+func (c Color) ToHSV() (h float32, s float32, v float32, a float32) {
+	c = c.clamped()
+	r, g, b := float64(c[0]), float64(c[1]), float64(c[2])
+	max := math.Max(r, math.Max(g, b))
+	min := math.Min(r, math.Min(g, b))
+	delta := max - min
+	var hue float64
+	switch {
+	case delta == 0:
+		hue = 0
+	case max == r:
+		hue = 60 * math.Mod((g-b)/delta, 6)
+	case max == g:
+		hue = 60 * ((b-r)/delta + 2)
+	default:
+		hue = 60 * ((r-g)/delta + 4)
+	}
+	if hue < 0 {
+		hue += 360
+	}
+	if max > 0 {
+		s = float32(delta / max)
+	}
+	return float32(hue), s, float32(max), c[3]
+}
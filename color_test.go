@@ -0,0 +1,146 @@
+package sysgapp
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestColorLerp(t *testing.T) {
+	from := Color{0.2, 0.4, 0.6, 0.8}
+	to := Color{1, 0, 0.5, 1}
+	tests := []struct {
+		name string
+		t    float32
+		want Color
+	}{
+		{"t=0", 0, from},
+		{"t=1", 1, to},
+		{"below 0 clamps to from", -2, from},
+		{"above 1 clamps to to", 3, to},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := from.Lerp(to, tt.t); got != tt.want {
+				t.Errorf("Lerp(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+func TestColorClampsAlpha(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Color
+		want Color
+	}{
+		{"WithAlpha above 1", ColorWhite.WithAlpha(1.5), Color{1, 1, 1, 1}},
+		{"WithAlpha below 0", ColorWhite.WithAlpha(-0.5), Color{1, 1, 1, 0}},
+		{"Lerp from out of range alpha", Color{0, 0, 0, 2}.Lerp(Color{0, 0, 0, 2}, 0.5), Color{0, 0, 0, 1}},
+		{"Multiply past 1", Color{1, 1, 1, 2}.Multiply(Color{1, 1, 1, 2}), Color{1, 1, 1, 1}},
+		{"Premultiply negative alpha", Color{1, 0.5, 0.25, -1}.Premultiply(), Color{0, 0, 0, 0}},
+		{"Premultiply", Color{1, 0.5, 0.25, 0.5}.Premultiply(), Color{0.5, 0.25, 0.125, 0.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+func TestColorFromHex(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want Color
+		out  string // ToHex of the parsed color
+	}{
+		{"#F80", Color{1, 0x88 / 255.0, 0, 1}, "#FF8800FF"},
+		{"#f808", Color{1, 0x88 / 255.0, 0, 0x88 / 255.0}, "#FF880088"},
+		{"#336699", Color{0.2, 0.4, 0.6, 1}, "#336699FF"},
+		{"#33669980", Color{0.2, 0.4, 0.6, 0x80 / 255.0}, "#33669980"},
+		{"336699", Color{0.2, 0.4, 0.6, 1}, "#336699FF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			got, err := ColorFromHex(tt.hex)
+			if err != nil {
+				t.Fatalf("ColorFromHex(%q) failed: %v", tt.hex, err)
+			}
+			if got != tt.want {
+				t.Errorf("ColorFromHex(%q) = %v, want %v", tt.hex, got, tt.want)
+			}
+			hex := got.ToHex()
+			if hex != tt.out {
+				t.Errorf("ToHex = %q, want %q", hex, tt.out)
+			}
+			if back, err := ColorFromHex(hex); err != nil || back != got {
+				t.Errorf("ColorFromHex(%q) = %v, %v, want the same color back", hex, back, err)
+			}
+		})
+	}
+}
+func TestColorFromHexErrors(t *testing.T) {
+	tests := []struct {
+		hex  string
+		says string // Part of the error describing the problem
+	}{
+		{"", "0 digits"},
+		{"#", "0 digits"},
+		{"#12", "2 digits"},
+		{"#12345", "5 digits"},
+		{"#1234567", "7 digits"},
+		{"#123456789", "9 digits"},
+		{"#GGG", "invalid digits"},
+		{"#12345z", "invalid digits \"5z\""},
+		{"#+1+1+1", "invalid digits"},
+		{"#1234 678", "invalid digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			c, err := ColorFromHex(tt.hex)
+			if err == nil {
+				t.Fatalf("ColorFromHex(%q) = %v, want an error", tt.hex, c)
+			}
+			if !strings.Contains(err.Error(), tt.says) || !strings.Contains(err.Error(), tt.hex) {
+				t.Errorf("error %q should name %q and say %q", err, tt.hex, tt.says)
+			}
+		})
+	}
+}
+func TestColorHSV(t *testing.T) {
+	near := func(a, b float32) bool { return math.Abs(float64(a-b)) < 1e-5 }
+	tests := []struct {
+		name       string
+		h, s, v, a float32
+		want       Color
+		backH      float32 // Hue ToHSV reports, hue 360 wraps to 0
+	}{
+		{"red at hue 0", 0, 1, 1, 1, Color{1, 0, 0, 1}, 0},
+		{"red at hue 360", 360, 1, 1, 0.5, Color{1, 0, 0, 0.5}, 0},
+		{"green", 120, 1, 1, 1, Color{0, 1, 0, 1}, 120},
+		{"blue", 240, 0.5, 0.8, 1, Color{0.4, 0.4, 0.8, 1}, 240},
+		{"negative hue wraps", -60, 1, 1, 1, Color{1, 0, 1, 1}, 300},
+		{"zero saturation is gray", 200, 0, 0.6, 1, Color{0.6, 0.6, 0.6, 1}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := ColorFromHSV(tt.h, tt.s, tt.v, tt.a)
+			for i := range c {
+				if !near(c[i], tt.want[i]) {
+					t.Fatalf("ColorFromHSV = %v, want %v", c, tt.want)
+				}
+			}
+			h, s, v, a := c.ToHSV()
+			if !near(h, tt.backH) || !near(s, tt.s) || !near(v, tt.v) || a != tt.a {
+				t.Errorf("ToHSV = %v %v %v %v, want %v %v %v %v", h, s, v, a, tt.backH, tt.s, tt.v, tt.a)
+			}
+			back := ColorFromHSV(h, s, v, a)
+			for i := range c {
+				if !near(back[i], c[i]) {
+					t.Errorf("round trip gave %v, want %v", back, c)
+					break
+				}
+			}
+		})
+	}
+}
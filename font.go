@@ -0,0 +1,59 @@
+package sysgapp
+
+import "sort"
+
+// GLYPH COVERAGE
+func (f *QuadPolyFont) HasGlyph(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// Runes returns the sorted code points the font has glyphs for
+func (f *QuadPolyFont) Runes() []rune {
+	return f.AppendRunes(make([]rune, 0, len(f.glyphs)))
+}
+
+// AppendRunes appends the font's sorted code points to dst and returns the extended slice,
+// pass a previous result's [:0] to reuse it without allocating on each call
+func (f *QuadPolyFont) AppendRunes(dst []rune) []rune {
+	start := len(dst)
+	for r := range f.glyphs {
+		dst = append(dst, r)
+	}
+	added := dst[start:]
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	return dst
+}
+
+// OutlineStrips strokes the border of the glyph's filled strips thickness font units wide, see TriStrips.Outline
+func (c *QuadGlyph) OutlineStrips(thickness float32) TriStrips {
+	return c.strips.Outline(thickness)
+}
+
+// Bounds returns the extent of the glyph's drawn geometry in font units, which can be tighter than its advance size
+func (c *QuadGlyph) Bounds() Rect2D {
+	return c.strips.Bounds()
+}
+
+// FONT METRICS
+// Glyphs sit in an em box scale.Y font units tall with the baseline along its bottom, matching
+// the layout used by the vector text functions. All metrics are in pixels at textSize.
+func (f *QuadPolyFont) LineHeight(textSize float32) float32 {
+	return (f.scale.Y() + f.lineSpacing) * textSize / f.scale.Y()
+}
+func (f *QuadPolyFont) Ascent(textSize float32) float32 {
+	return textSize
+}
+
+// Descent returns how far the lowest glyph reaches below the baseline, it walks every glyph so cache it outside of draw loops
+func (f *QuadPolyFont) Descent(textSize float32) float32 {
+	lowest := f.scale.Y()
+	for _, glyph := range f.glyphs {
+		bounds := glyph.Bounds()
+		lowest = fmax(lowest, bounds.Y()+bounds.H())
+	}
+	return (lowest - f.scale.Y()) * textSize / f.scale.Y()
+}
+func (f *QuadPolyFont) SpaceWidth(textSize float32) float32 {
+	return f.scale.W() * textSize / f.scale.Y()
+}
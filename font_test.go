@@ -0,0 +1,77 @@
+package sysgapp
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAppendRunesKeepsExistingContents(t *testing.T) {
+	font := &QuadPolyFont{glyphs: map[rune]*QuadGlyph{'c': {}, 'a': {}, 'b': {}}}
+	got := font.AppendRunes([]rune{'z'})
+	if want := []rune{'z', 'a', 'b', 'c'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("AppendRunes = %q, want %q", got, want)
+	}
+	if got := font.Runes(); !reflect.DeepEqual(got, []rune{'a', 'b', 'c'}) {
+		t.Errorf("Runes = %q, want \"abc\"", got)
+	}
+}
+func TestDrawTextInUnregisteredFont(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.SetMissingFontWarnings(false)
+	s.fonts = map[FontIndex]*QuadPolyFont{0: {scale: Vec2{10, 20}, glyphs: map[rune]*QuadGlyph{}}}
+	const unknown FontIndex = 99
+	if s.HasFont(unknown) || !s.HasFont(0) {
+		t.Fatalf("HasFont(%d) = %v and HasFont(0) = %v, want false and true", unknown, s.HasFont(unknown), s.HasFont(0))
+	}
+	s.DrawQuadVecText(unknown, "x", Vec2{}, &ColorWhite, 20)
+	s.DrawQuadVecTextRotated(unknown, "x", Vec2{}, &ColorWhite, 20, 1)
+	if h := s.DrawQuadVecTextWrapped(unknown, "x", Vec2{}, &ColorWhite, 20, 100); h != 0 {
+		t.Errorf("wrapped text in an unregistered font is %v tall, want 0", h)
+	}
+	if n := lib.BatchVertexCount(); n != 0 {
+		t.Fatalf("text in an unregistered font drew %d vertices without a default font", n)
+	}
+	// With a default font the text draws in it instead, here as a missing glyph box
+	s.SetDefaultFont(0)
+	s.DrawQuadVecText(unknown, "x", Vec2{}, &ColorWhite, 20)
+	if n := lib.BatchVertexCount(); n != 4 {
+		t.Errorf("text drawn in the default font added %d vertices, want a 4 vertex box", n)
+	}
+}
+func TestBakeFontToAtlas(t *testing.T) {
+	s, lib := newFakeSolution()
+	square := TriStrips{{{0, 0}, {0, 1}, {1, 0}, {1, 1}}}
+	s.fonts = map[FontIndex]*QuadPolyFont{0: {scale: Vec2{1, 1}, glyphs: map[rune]*QuadGlyph{
+		'a': {size: Vec2{1, 1}, strips: square},
+		// Far wider than the largest atlas
+		'w': {size: Vec2{1000, 1}, strips: square},
+	}}}
+	// None of the frame's state may reach the atlas
+	s.SetCamera(Vec2{50, 50}, 2, 0.5)
+	s.PushTransform(Vec2{10, 10}, 0, Vec2{3, 3})
+	s.PushOpacity(0.5)
+	s.SetPixelSnap(true)
+	texIndex, atlas := s.BakeFontToAtlas(0, 16)
+	if atlas == nil {
+		t.Fatal("baking a registered font returned no atlas")
+	}
+	bFont := s.bitmapFonts[0]
+	if _, ok := bFont.glyphs['w']; ok {
+		t.Error("a glyph wider than the atlas was baked")
+	}
+	region, ok := bFont.glyphs['a']
+	if !ok {
+		t.Fatal("glyph 'a' was not baked")
+	}
+	want := atlas.Region(region).Points()[0]
+	if len(lib.flushed) == 0 || lib.flushed[0].pos != want || lib.flushed[0].color[3] != 1 {
+		t.Errorf("baked glyph vertices %+v, want the first at %v with full alpha", lib.flushed, want)
+	}
+	if s.space != WorldSpace || s.GetOpacity() != 0.5 || !s.pixelSnap {
+		t.Errorf("baking left space %d, opacity %v, pixel snap %v", s.space, s.GetOpacity(), s.pixelSnap)
+	}
+	again, _ := s.BakeFontToAtlas(0, 24)
+	if again != texIndex || s.bitmapFonts[0].surfIndex != bFont.surfIndex {
+		t.Errorf("rebaking used texture %d, want the first bake's %d", again, texIndex)
+	}
+}
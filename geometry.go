@@ -0,0 +1,429 @@
+package sysgapp
+
+import "math"
+
+const miterLimitDefault = 4
+
+// StarPoints returns the alternating outer and inner vertices of a star, starting with an outer tip
+func StarPoints(points int, innerRadius float32, outerRadius float32, center Vec2, rotation float32) []Vec2 {
+	if points < 2 {
+		return nil
+	}
+	verts := make([]Vec2, points*2)
+	step := math.Pi / float64(points)
+	for i := range verts {
+		radius := outerRadius
+		if i%2 == 1 {
+			radius = innerRadius
+		}
+		angle := float64(rotation) + step*float64(i)
+		verts[i] = Vec2{
+			center.X() + radius*float32(math.Cos(angle)),
+			center.Y() + radius*float32(math.Sin(angle)),
+		}
+	}
+	return verts
+}
+
+// appendDedupedPath appends points to dst, dropping consecutive points that coincide and the
+// closing point of a closed path
+func appendDedupedPath(dst []Vec2, points []Vec2, closed bool) []Vec2 {
+	start := len(dst)
+	for _, p := range points {
+		if len(dst) > start && dst[len(dst)-1] == p {
+			continue
+		}
+		dst = append(dst, p)
+	}
+	if closed && len(dst)-start > 1 && dst[start] == dst[len(dst)-1] {
+		dst = dst[:len(dst)-1]
+	}
+	return dst
+}
+
+// clampSweep limits an angular sweep (radians) to at most one full turn in either direction
+func clampSweep(startAngle float32, endAngle float32) float32 {
+	sweep := endAngle - startAngle
+	if sweep > 2*math.Pi {
+		sweep = 2 * math.Pi
+	} else if sweep < -2*math.Pi {
+		sweep = -2 * math.Pi
+	}
+	return sweep
+}
+
+// arcSegments derives a segment count from the circumference the same way DrawCircle does, scaled by the swept fraction
+func arcSegments(radius float32, sweep float32, resolution float32) int {
+	fraction := float32(math.Abs(float64(sweep))) / (2 * math.Pi)
+	segments := int(math.Ceil(float64(Circumference(radius) / resolution * fraction)))
+	if segments < 1 {
+		segments = 1
+	}
+	return segments
+}
+
+// ForEachPointOnCircle calls fn with each of the points PointsOnCircle would return, starting at
+// rotation and stepping evenly around the circle, without allocating a slice for them
+func ForEachPointOnCircle(count float32, radius float32, center Vec2, rotation float32, fn func(i int, p Vec2)) {
+	n := int(FFLoor(count))
+	for i := 0; i < n; i++ {
+		sin, cos := math.Sincos(float64(rotation) + 2*math.Pi*float64(i)/float64(n))
+		fn(i, Vec2{center.X() + radius*float32(cos), center.Y() + radius*float32(sin)})
+	}
+}
+
+// ForEachPointOnRing calls fn with the points PointsOnRing would return, an inner then outer point per step
+func ForEachPointOnRing(count float32, innerRadius float32, outerRadius float32, center Vec2, rotation float32, fn func(i int, p Vec2)) {
+	n := int(FFLoor(count))
+	for i := 0; i < n; i++ {
+		sin, cos := math.Sincos(float64(rotation) + 2*math.Pi*float64(i)/float64(n))
+		fn(i*2, Vec2{center.X() + innerRadius*float32(cos), center.Y() + innerRadius*float32(sin)})
+		fn(i*2+1, Vec2{center.X() + outerRadius*float32(cos), center.Y() + outerRadius*float32(sin)})
+	}
+}
+
+// PointsOnCircleN is PointsOnCircle with an exact side count, returning nil for fewer than 3 sides
+func PointsOnCircleN(sides int, radius float32, center Vec2, rotation float32) []Vec2 {
+	if sides < 3 {
+		return nil
+	}
+	return PointsOnCircle(float32(sides), radius, center, rotation)
+}
+
+// ArcPoints returns segments+1 points along an arc starting at startAngle and sweeping by sweep radians
+func ArcPoints(segments int, radius float32, center Vec2, startAngle float32, sweep float32) []Vec2 {
+	points := make([]Vec2, segments+1)
+	for i := range points {
+		angle := float64(startAngle) + float64(sweep)*float64(i)/float64(segments)
+		points[i] = Vec2{
+			center.X() + radius*float32(math.Cos(angle)),
+			center.Y() + radius*float32(math.Sin(angle)),
+		}
+	}
+	return points
+}
+
+// RoundedRectPoints traces the outline of a rect with quarter-circle corners, clockwise from the top-left corner.
+// radius is clamped to half the rect's smaller side.
+func RoundedRectPoints(rect Rect2D, radius float32) []Vec2 {
+	if half := rect.W() / 2; radius > half {
+		radius = half
+	}
+	if half := rect.H() / 2; radius > half {
+		radius = half
+	}
+	if radius < 0 {
+		radius = 0
+	}
+	l, t := rect.X()+radius, rect.Y()+radius
+	r, b := rect.X()+rect.W()-radius, rect.Y()+rect.H()-radius
+	segments := arcSegments(radius, math.Pi/2, 2)
+	points := make([]Vec2, 0, (segments+1)*4)
+	points = append(points, ArcPoints(segments, radius, Vec2{l, t}, math.Pi, math.Pi/2)...)
+	points = append(points, ArcPoints(segments, radius, Vec2{r, t}, math.Pi*3/2, math.Pi/2)...)
+	points = append(points, ArcPoints(segments, radius, Vec2{r, b}, 0, math.Pi/2)...)
+	points = append(points, ArcPoints(segments, radius, Vec2{l, b}, math.Pi/2, math.Pi/2)...)
+	return points
+}
+
+// segmentsCross reports whether segments ab and cd intersect, including touching endpoints
+func segmentsCross(a Vec2, b Vec2, c Vec2, d Vec2) bool {
+	d1 := b.Sub(a).Cross(c.Sub(a))
+	d2 := b.Sub(a).Cross(d.Sub(a))
+	d3 := d.Sub(c).Cross(a.Sub(c))
+	d4 := d.Sub(c).Cross(b.Sub(c))
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	onSegment := func(p, q, r Vec2) bool {
+		return r.X() >= fmin(p.X(), q.X()) && r.X() <= fmax(p.X(), q.X()) && r.Y() >= fmin(p.Y(), q.Y()) && r.Y() <= fmax(p.Y(), q.Y())
+	}
+	return (d1 == 0 && onSegment(a, b, c)) || (d2 == 0 && onSegment(a, b, d)) || (d3 == 0 && onSegment(c, d, a)) || (d4 == 0 && onSegment(c, d, b))
+}
+func fmin(a float32, b float32) float32 {
+	if a < b {
+		return a
+	}
+	return b
+}
+func fmax(a float32, b float32) float32 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// polygonArea returns the signed area of a polygon, positive when its points wind counter-clockwise in a y-up frame
+func polygonArea(points []Vec2) float32 {
+	area := float32(0)
+	for i := range points {
+		area += points[i].Cross(points[(i+1)%len(points)])
+	}
+	return area / 2
+}
+
+// OffsetPolygon moves every edge of a closed polygon of either winding distance along its outward
+// normal, negative distances inset, and places each vertex where its two moved edges meet. A
+// corner's vertex moves at most miterLimitDefault times distance, and no further than its shorter
+// edge is long, so sharp convex corners are clamped and tight concave ones collapse inward
+// instead of crossing over their neighbours.
+func OffsetPolygon(points []Vec2, distance float32) []Vec2 {
+	points = appendDedupedPath(nil, points, true)
+	n := len(points)
+	if n < 3 || distance == 0 {
+		return append([]Vec2(nil), points...)
+	}
+	side := float32(1)
+	if polygonArea(points) > 0 {
+		side = -1
+	}
+	normal := func(a Vec2, b Vec2) Vec2 {
+		_, p := b.Sub(a).Norm().Perp()
+		return Vec2{p.X() * side, p.Y() * side}
+	}
+	offset := make([]Vec2, n)
+	for i := range points {
+		prev, cur, next := points[(i+n-1)%n], points[i], points[(i+1)%n]
+		n1, n2 := normal(prev, cur), normal(cur, next)
+		sum := n1.Add(n2)
+		if sum.Len() == 0 {
+			// The edges double back on themselves, push straight out from the first
+			offset[i] = Vec2{cur.X() + n1.X()*distance, cur.Y() + n1.Y()*distance}
+			continue
+		}
+		// The moved edges meet distance / cos(half the turn) along the bisector
+		bisector := sum.Norm()
+		length := distance / bisector.Dot(n1)
+		limit := float32(math.Abs(float64(distance))) * miterLimitDefault
+		limit = fmin(limit, fmin(cur.Sub(prev).Len(), next.Sub(cur).Len()))
+		if d := float32(math.Abs(float64(distance))); limit < d {
+			limit = d
+		}
+		if length > limit {
+			length = limit
+		} else if length < -limit {
+			length = -limit
+		}
+		offset[i] = Vec2{cur.X() + bisector.X()*length, cur.Y() + bisector.Y()*length}
+	}
+	return offset
+}
+
+// polygonSelfIntersects reports whether any two non-adjacent edges of a closed polygon touch
+func polygonSelfIntersects(points []Vec2) bool {
+	n := len(points)
+	for i := 0; i < n; i++ {
+		a, b := points[i], points[(i+1)%n]
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentsCross(a, b, points[j], points[(j+1)%n]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// TriangulatePolygon splits a simple polygon of either winding into triangles by ear clipping,
+// returning indexes into points three per triangle. It returns false, and no triangles, for
+// polygons with fewer than 3 distinct points, zero area, or self-intersecting edges.
+func TriangulatePolygon(points []Vec2) ([]int, bool) {
+	n := len(points)
+	for n > 1 && points[n-1] == points[0] {
+		n--
+	}
+	if n < 3 || polygonSelfIntersects(points[:n]) {
+		return nil, false
+	}
+	area := polygonArea(points[:n])
+	if area == 0 {
+		return nil, false
+	}
+	remaining := make([]int, n)
+	for i := range remaining {
+		remaining[i] = i
+	}
+	if area < 0 {
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			remaining[i], remaining[j] = remaining[j], remaining[i]
+		}
+	}
+	tris := make([]int, 0, (n-2)*3)
+	for len(remaining) > 3 {
+		clipped := false
+		for i := range remaining {
+			prev := remaining[(i-1+len(remaining))%len(remaining)]
+			cur := remaining[i]
+			next := remaining[(i+1)%len(remaining)]
+			a, b, c := points[prev], points[cur], points[next]
+			cross := b.Sub(a).Cross(c.Sub(b))
+			if cross < 0 {
+				continue
+			}
+			if cross > 0 {
+				ear := true
+				for _, other := range remaining {
+					if other == prev || other == cur || other == next {
+						continue
+					}
+					if pointInTriangle(points[other], a, b, c) {
+						ear = false
+						break
+					}
+				}
+				if !ear {
+					continue
+				}
+				tris = append(tris, prev, cur, next)
+			}
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			clipped = true
+			break
+		}
+		if !clipped {
+			return nil, false
+		}
+	}
+	if points[remaining[1]].Sub(points[remaining[0]]).Cross(points[remaining[2]].Sub(points[remaining[1]])) != 0 {
+		tris = append(tris, remaining[0], remaining[1], remaining[2])
+	}
+	return tris, true
+}
+
+// pointInTriangle reports whether p lies inside or on the edges of the counter-clockwise triangle abc
+func pointInTriangle(p Vec2, a Vec2, b Vec2, c Vec2) bool {
+	return b.Sub(a).Cross(p.Sub(a)) >= 0 && c.Sub(b).Cross(p.Sub(b)) >= 0 && a.Sub(c).Cross(p.Sub(c)) >= 0
+}
+
+// bezierSegments picks a flattening segment count from the length of a curve's control polygon
+func bezierSegments(controls ...Vec2) int {
+	length := float32(0)
+	for i := 1; i < len(controls); i++ {
+		length += controls[i].Sub(controls[i-1]).Len()
+	}
+	segments := int(length / 8)
+	if segments < 4 {
+		segments = 4
+	} else if segments > 128 {
+		segments = 128
+	}
+	return segments
+}
+func QuadraticBezierPoints(p0 Vec2, p1 Vec2, p2 Vec2, segments int) []Vec2 {
+	if segments <= 0 {
+		segments = bezierSegments(p0, p1, p2)
+	}
+	points := make([]Vec2, segments+1)
+	for i := range points {
+		t := float32(i) / float32(segments)
+		u := 1 - t
+		points[i] = p0.Mag(u * u).Add(p1.Mag(2 * u * t)).Add(p2.Mag(t * t))
+	}
+	return points
+}
+func CubicBezierPoints(p0 Vec2, p1 Vec2, p2 Vec2, p3 Vec2, segments int) []Vec2 {
+	if segments <= 0 {
+		segments = bezierSegments(p0, p1, p2, p3)
+	}
+	points := make([]Vec2, segments+1)
+	for i := range points {
+		t := float32(i) / float32(segments)
+		u := 1 - t
+		points[i] = p0.Mag(u * u * u).Add(p1.Mag(3 * u * u * t)).Add(p2.Mag(3 * u * t * t)).Add(p3.Mag(t * t * t))
+	}
+	return points
+}
+
+// EllipsePoints returns count points around an ellipse, rotated by rotation radians about its center
+func EllipsePoints(count int, radiusX float32, radiusY float32, center Vec2, rotation float32) []Vec2 {
+	rot := Affine2DRotate(rotation).Then(Affine2DTranslate(center))
+	points := make([]Vec2, count)
+	for i := range points {
+		angle := 2 * math.Pi * float64(i) / float64(count)
+		points[i] = rot.Apply(Vec2{radiusX * float32(math.Cos(angle)), radiusY * float32(math.Sin(angle))})
+	}
+	return points
+}
+
+// CircleContains reports whether p is inside or exactly on the circle
+func CircleContains(center Vec2, radius float32, p Vec2) bool {
+	d := p.Sub(center)
+	return d.Dot(d) <= radius*radius
+}
+
+// PolygonContains reports whether p is inside the polygon using even-odd ray casting, so it
+// works for concave outlines. Like Rect2D.Contains, points on left and top facing edges count
+// as inside and points on right and bottom facing edges don't.
+func PolygonContains(points []Vec2, p Vec2) bool {
+	inside := false
+	for i, j := 0, len(points)-1; i < len(points); j, i = i, i+1 {
+		a, b := points[i], points[j]
+		if (a.Y() > p.Y()) == (b.Y() > p.Y()) {
+			continue
+		}
+		crossX := a.X() + (p.Y()-a.Y())*(b.X()-a.X())/(b.Y()-a.Y())
+		if p.X() < crossX {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// Collisions
+// CirclesOverlap reports whether two circles share area, circles that only touch don't overlap
+func CirclesOverlap(c1 Vec2, r1 float32, c2 Vec2, r2 float32) bool {
+	d := c2.Sub(c1)
+	return d.Dot(d) < (r1+r2)*(r1+r2)
+}
+
+// closestPointInRect clamps p into rect
+func closestPointInRect(p Vec2, rect Rect2D) Vec2 {
+	return Vec2{
+		fmin(fmax(p.X(), rect.X()), rect.X()+rect.W()),
+		fmin(fmax(p.Y(), rect.Y()), rect.Y()+rect.H()),
+	}
+}
+
+// CircleRectOverlap reports whether the circle and rect share area. Near a corner this measures
+// to the corner point itself, so a circle inside the rect's bounding square there may still miss.
+func CircleRectOverlap(center Vec2, radius float32, rect Rect2D) bool {
+	d := center.Sub(closestPointInRect(center, rect))
+	return d.Dot(d) < radius*radius
+}
+
+// ResolveCircleRect returns the shortest translation that moves the circle out of the rect, or a
+// zero vector if they don't overlap. A circle past a corner is pushed directly away from the corner;
+// one whose center is inside the rect is pushed out through the nearest edge.
+func ResolveCircleRect(center Vec2, radius float32, rect Rect2D) Vec2 {
+	d := center.Sub(closestPointInRect(center, rect))
+	distSq := d.Dot(d)
+	if distSq >= radius*radius {
+		return Vec2{}
+	}
+	if distSq > 0 {
+		dist := d.Len()
+		push := (radius - dist) / dist
+		return Vec2{d.X() * push, d.Y() * push}
+	}
+	left := center.X() - rect.X()
+	right := rect.X() + rect.W() - center.X()
+	top := center.Y() - rect.Y()
+	bottom := rect.Y() + rect.H() - center.Y()
+	nearest := fmin(fmin(left, right), fmin(top, bottom))
+	switch nearest {
+	case left:
+		return Vec2{-(left + radius), 0}
+	case right:
+		return Vec2{right + radius, 0}
+	case top:
+		return Vec2{0, -(top + radius)}
+	}
+	return Vec2{0, bottom + radius}
+}
+
+// pointInsideTriangle is pointInTriangle excluding the edges
+func pointInsideTriangle(p Vec2, a Vec2, b Vec2, c Vec2) bool {
+	return b.Sub(a).Cross(p.Sub(a)) > 0 && c.Sub(b).Cross(p.Sub(b)) > 0 && a.Sub(c).Cross(p.Sub(c)) > 0
+}
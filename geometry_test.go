@@ -0,0 +1,182 @@
+package sysgapp
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTriangulatePolygon(t *testing.T) {
+	lShape := []Vec2{{0, 0}, {2, 0}, {2, 1}, {1, 1}, {1, 3}, {0, 3}}
+	reversed := make([]Vec2, len(lShape))
+	for i, p := range lShape {
+		reversed[len(lShape)-1-i] = p
+	}
+	tests := []struct {
+		name   string
+		points []Vec2
+		ok     bool
+	}{
+		{"L shape", lShape, true},
+		{"L shape reversed winding", reversed, true},
+		{"L shape closed", append(append([]Vec2{}, lShape...), lShape[0]), true},
+		{"square", []Vec2{{0, 0}, {1, 0}, {1, 1}, {0, 1}}, true},
+		{"bowtie", []Vec2{{0, 0}, {1, 1}, {1, 0}, {0, 1}}, false},
+		{"collinear", []Vec2{{0, 0}, {1, 0}, {2, 0}}, false},
+		{"two points", []Vec2{{0, 0}, {1, 0}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tris, ok := TriangulatePolygon(tt.points)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				if len(tris) != 0 {
+					t.Errorf("returned %d indexes for a rejected polygon", len(tris))
+				}
+				return
+			}
+			n := len(tt.points)
+			if tt.points[n-1] == tt.points[0] {
+				n--
+			}
+			if len(tris) != (n-2)*3 {
+				t.Fatalf("got %d triangles, want %d", len(tris)/3, n-2)
+			}
+			var covered float32
+			for i := 0; i < len(tris); i += 3 {
+				a, b, c := tt.points[tris[i]], tt.points[tris[i+1]], tt.points[tris[i+2]]
+				centroid := Vec2{(a.X() + b.X() + c.X()) / 3, (a.Y() + b.Y() + c.Y()) / 3}
+				if !PolygonContains(tt.points[:n], centroid) {
+					t.Errorf("triangle %v %v %v lies outside the polygon", a, b, c)
+				}
+				covered += float32(math.Abs(float64(b.Sub(a).Cross(c.Sub(a))))) / 2
+			}
+			if want := float32(math.Abs(float64(polygonArea(tt.points[:n])))); math.Abs(float64(covered-want)) > 1e-4 {
+				t.Errorf("triangles cover an area of %v, want %v", covered, want)
+			}
+		})
+	}
+}
+func TestCircleRectCorners(t *testing.T) {
+	rect := NewRect2D(Vec2{0, 0}, Vec2{10, 10})
+	tests := []struct {
+		name    string
+		center  Vec2
+		radius  float32
+		overlap bool
+	}{
+		// Inside the rect's bounds expanded by the radius, but further than radius from the corner point
+		{"misses bottom right corner", Vec2{11.5, 11.5}, 2, false},
+		{"misses top left corner", Vec2{-1.5, -1.5}, 2, false},
+		{"hits bottom right corner", Vec2{11, 11}, 2, true},
+		{"hits top right corner", Vec2{11, -1}, 2, true},
+		{"hits edge", Vec2{11, 5}, 2, true},
+		{"center inside", Vec2{9, 5}, 2, true},
+		{"touches edge only", Vec2{12, 5}, 2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CircleRectOverlap(tt.center, tt.radius, rect); got != tt.overlap {
+				t.Fatalf("CircleRectOverlap = %v, want %v", got, tt.overlap)
+			}
+			push := ResolveCircleRect(tt.center, tt.radius, rect)
+			if !tt.overlap {
+				if push != (Vec2{}) {
+					t.Errorf("ResolveCircleRect = %v for circles that don't overlap, want zero", push)
+				}
+				return
+			}
+			moved := tt.center.Add(push)
+			d := moved.Sub(closestPointInRect(moved, rect))
+			if dist := d.Len(); math.Abs(float64(dist-tt.radius)) > 1e-4 {
+				t.Errorf("resolved circle is %v from the rect, want exactly its radius %v", dist, tt.radius)
+			}
+		})
+	}
+}
+func TestResolveCircleRectCornerPushesAwayFromCorner(t *testing.T) {
+	rect := NewRect2D(Vec2{0, 0}, Vec2{10, 10})
+	push := ResolveCircleRect(Vec2{11, 11}, 2, rect)
+	if push.X() <= 0 || math.Abs(float64(push.X()-push.Y())) > 1e-6 {
+		t.Errorf("push = %v, want equal positive components directly away from the corner", push)
+	}
+}
+func TestCirclesOverlap(t *testing.T) {
+	if !CirclesOverlap(Vec2{0, 0}, 1, Vec2{1.5, 0}, 1) {
+		t.Error("circles 1.5 apart with radius 1 should overlap")
+	}
+	if CirclesOverlap(Vec2{0, 0}, 1, Vec2{2, 0}, 1) {
+		t.Error("touching circles should not overlap")
+	}
+}
+func TestPolygonContainsConcave(t *testing.T) {
+	// A U open at the top, with a notch from x 10 to 20 down to y 20
+	u := []Vec2{{0, 0}, {10, 0}, {10, 20}, {20, 20}, {20, 0}, {30, 0}, {30, 30}, {0, 30}}
+	tests := []struct {
+		name string
+		p    Vec2
+		want bool
+	}{
+		{"in the notch", Vec2{15, 10}, false},
+		{"above the notch", Vec2{15, -1}, false},
+		{"left arm", Vec2{5, 10}, true},
+		{"right arm", Vec2{25, 10}, true},
+		{"base", Vec2{15, 25}, true},
+		{"outside right", Vec2{31, 10}, false},
+		// Left and top facing edges are inside, right and bottom facing ones are not
+		{"outer left edge", Vec2{0, 10}, true},
+		{"outer right edge", Vec2{30, 10}, false},
+		{"left arm's right edge", Vec2{10, 10}, false},
+		{"right arm's left edge", Vec2{20, 10}, true},
+		{"notch floor", Vec2{15, 20}, true},
+		{"bottom edge", Vec2{15, 30}, false},
+		{"left arm top edge", Vec2{5, 0}, true},
+		{"top left vertex", Vec2{0, 0}, true},
+		{"left arm top right vertex", Vec2{10, 0}, false},
+		{"right arm top left vertex", Vec2{20, 0}, true},
+		{"top right vertex", Vec2{30, 0}, false},
+		{"bottom left vertex", Vec2{0, 30}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PolygonContains(u, tt.p); got != tt.want {
+				t.Errorf("PolygonContains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+}
+func TestRect2DContainsEdges(t *testing.T) {
+	rect := NewRect2D(Vec2{10, 10}, Vec2{20, 20})
+	tests := []struct {
+		name string
+		p    Vec2
+		want bool
+	}{
+		{"center", Vec2{20, 20}, true},
+		{"left edge", Vec2{10, 20}, true},
+		{"top edge", Vec2{20, 10}, true},
+		{"right edge", Vec2{30, 20}, false},
+		{"bottom edge", Vec2{20, 30}, false},
+		{"top left corner", Vec2{10, 10}, true},
+		{"top right corner", Vec2{30, 10}, false},
+		{"bottom left corner", Vec2{10, 30}, false},
+		{"bottom right corner", Vec2{30, 30}, false},
+		{"just inside the right edge", Vec2{29.999, 20}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rect.Contains(tt.p); got != tt.want {
+				t.Errorf("Contains(%v) = %v, want %v", tt.p, got, tt.want)
+			}
+		})
+	}
+	// A point on the border of two touching rects belongs to exactly one
+	right := NewRect2D(Vec2{30, 10}, Vec2{20, 20})
+	if p := (Vec2{30, 20}); rect.Contains(p) == right.Contains(p) {
+		t.Errorf("shared border point %v is in both or neither rect", p)
+	}
+	if !CircleContains(Vec2{0, 0}, 5, Vec2{3, 4}) {
+		t.Error("CircleContains excludes a point exactly on the circle")
+	}
+}
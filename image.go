@@ -0,0 +1,81 @@
+package sysgapp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+
+	V "github.com/gabe-lee/genvecs"
+)
+
+var ErrUnknownImageSize = errors.New("sysgapp: could not read image dimensions from header")
+
+// imageSize reads the pixel dimensions of encoded image data from its header without decoding the pixels
+func imageSize(data []byte, imgType ImageType) (V.F32Vec2, error) {
+	switch imgType {
+	case PNG, JPEG:
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return V.F32Vec2{}, fmt.Errorf("sysgapp: reading image header: %w", err)
+		}
+		return V.F32Vec2{float32(cfg.Width), float32(cfg.Height)}, nil
+	case BMP:
+		return bmpSize(data)
+	case WEBP:
+		return webpSize(data)
+	case RawRGBA:
+		return V.F32Vec2{}, errors.New("sysgapp: raw RGBA data has no header, pass its size to NewTexture")
+	}
+	return V.F32Vec2{}, fmt.Errorf("sysgapp: unsupported image type %d", imgType)
+}
+func bmpSize(data []byte) (V.F32Vec2, error) {
+	if len(data) < 26 || data[0] != 'B' || data[1] != 'M' {
+		return V.F32Vec2{}, ErrUnknownImageSize
+	}
+	w := int32(binary.LittleEndian.Uint32(data[18:22]))
+	h := int32(binary.LittleEndian.Uint32(data[22:26]))
+	if h < 0 {
+		// Negative height marks top-down row order
+		h = -h
+	}
+	return V.F32Vec2{float32(w), float32(h)}, nil
+}
+func webpSize(data []byte) (V.F32Vec2, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return V.F32Vec2{}, ErrUnknownImageSize
+	}
+	switch string(data[12:16]) {
+	case "VP8 ":
+		w := binary.LittleEndian.Uint16(data[26:28]) & 0x3fff
+		h := binary.LittleEndian.Uint16(data[28:30]) & 0x3fff
+		return V.F32Vec2{float32(w), float32(h)}, nil
+	case "VP8L":
+		if data[20] != 0x2f {
+			return V.F32Vec2{}, ErrUnknownImageSize
+		}
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		w := bits&0x3fff + 1
+		h := (bits>>14)&0x3fff + 1
+		return V.F32Vec2{float32(w), float32(h)}, nil
+	case "VP8X":
+		w := uint32(data[24]) | uint32(data[25])<<8 | uint32(data[26])<<16
+		h := uint32(data[27]) | uint32(data[28])<<8 | uint32(data[29])<<16
+		return V.F32Vec2{float32(w + 1), float32(h + 1)}, nil
+	}
+	return V.F32Vec2{}, ErrUnknownImageSize
+}
+
+// validate checks that raw pixel data matches the texture size, encoded types are checked when decoded
+func (t *Texture) validate() error {
+	if t.imgType != RawRGBA {
+		return nil
+	}
+	w, h := t.Dimensions()
+	if len(t.data) != w*h*4 {
+		return fmt.Errorf("sysgapp: raw RGBA texture has %d bytes, %dx%d pixels needs %d", len(t.data), w, h, w*h*4)
+	}
+	return nil
+}
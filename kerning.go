@@ -0,0 +1,12 @@
+package sysgapp
+
+// KERNING
+// KerningTable maps consecutive rune pairs to an advance adjustment in font units, negative pulls the pair closer
+type KerningTable map[[2]rune]float32
+
+func (k KerningTable) SetKerning(a rune, b rune, adjust float32) {
+	k[[2]rune{a, b}] = adjust
+}
+func (k KerningTable) Kerning(a rune, b rune) float32 {
+	return k[[2]rune{a, b}]
+}
@@ -0,0 +1,72 @@
+package sysgapp
+
+import "sort"
+
+// DRAW LAYERS
+type layeredOp struct {
+	layer      int
+	depth      int
+	op         func()
+	space      DrawSpace
+	view       Affine2D
+	viewActive bool
+	opacity    float32
+}
+
+// DrawOnLayer records op and runs it when the current draw target (or frame) ends, after every
+// op recorded there on a lower layer and in call order within a layer. The draw space, view
+// transforms, and opacity active at the call are restored while op runs; other state, like clip
+// rects, masks, and blend modes, is whatever is current at the flush, so push it inside op instead.
+//
+// Each op is held until its flush together with everything its closure captures, so recording
+// many short-lived ops with large captured values costs memory for the rest of the target.
+// DrawOnLayer called inside a layered op runs immediately.
+func (s *SystemSolution) DrawOnLayer(layer int, op func()) {
+	if s.layerReplay {
+		op()
+		return
+	}
+	s.layerOps = append(s.layerOps, layeredOp{
+		layer:      layer,
+		depth:      len(s.targets),
+		op:         op,
+		space:      s.space,
+		view:       s.view,
+		viewActive: s.viewActive,
+		opacity:    s.GetOpacity(),
+	})
+}
+
+// flushLayers runs the ops recorded for the current draw target sorted by layer, keeping ops
+// recorded for outer targets queued
+func (s *SystemSolution) flushLayers() {
+	if len(s.layerOps) == 0 || s.layerReplay {
+		return
+	}
+	depth := len(s.targets)
+	var run []layeredOp
+	kept := s.layerOps[:0]
+	for _, op := range s.layerOps {
+		if op.depth == depth {
+			run = append(run, op)
+		} else {
+			kept = append(kept, op)
+		}
+	}
+	for i := len(kept); i < len(s.layerOps); i++ {
+		s.layerOps[i] = layeredOp{}
+	}
+	s.layerOps = kept
+	sort.SliceStable(run, func(i, j int) bool {
+		return run[i].layer < run[j].layer
+	})
+	space, view, viewActive, opacities := s.space, s.view, s.viewActive, s.opacities
+	s.layerReplay = true
+	for _, op := range run {
+		s.space, s.view, s.viewActive = op.space, op.view, op.viewActive
+		s.opacities = []float32{op.opacity}
+		op.op()
+	}
+	s.layerReplay = false
+	s.space, s.view, s.viewActive, s.opacities = space, view, viewActive, opacities
+}
@@ -0,0 +1,69 @@
+package sysgapp
+
+// Intersect returns where two segments cross, end points included. Collinear overlapping
+// segments return the overlap point closest to l.A(), parallel segments never intersect.
+func (l Line2D) Intersect(other Line2D) (Vec2, bool) {
+	a, r := l.A(), l.B().Sub(l.A())
+	c, d := other.A(), other.B().Sub(other.A())
+	denom := r.Cross(d)
+	ac := c.Sub(a)
+	if denom == 0 {
+		if ac.Cross(r) != 0 {
+			return Vec2{}, false
+		}
+		lenSq := r.Dot(r)
+		if lenSq == 0 {
+			// l is a single point, it lies on other only where other passes through it
+			if d.Dot(d) == 0 {
+				return a, a == c
+			}
+			t := a.Sub(c).Dot(d) / d.Dot(d)
+			return a, t >= 0 && t <= 1
+		}
+		t0 := ac.Dot(r) / lenSq
+		t1 := other.B().Sub(a).Dot(r) / lenSq
+		start, end := fmax(fmin(t0, t1), 0), fmin(fmax(t0, t1), 1)
+		if start > end {
+			return Vec2{}, false
+		}
+		return pointAlong(a, r, start), true
+	}
+	t := ac.Cross(d) / denom
+	u := ac.Cross(r) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return Vec2{}, false
+	}
+	return pointAlong(a, r, t), true
+}
+
+// IntersectRay returns the first point where a ray from origin heading along dir meets the
+// segment. A ray running along the segment returns the segment point closest to origin.
+func (l Line2D) IntersectRay(origin Vec2, dir Vec2) (Vec2, bool) {
+	if dir.Dot(dir) == 0 {
+		return Vec2{}, false
+	}
+	a, r := l.A(), l.B().Sub(l.A())
+	oa := a.Sub(origin)
+	denom := dir.Cross(r)
+	if denom == 0 {
+		if oa.Cross(dir) != 0 {
+			return Vec2{}, false
+		}
+		dirSq := dir.Dot(dir)
+		ta := oa.Dot(dir) / dirSq
+		tb := l.B().Sub(origin).Dot(dir) / dirSq
+		if fmax(ta, tb) < 0 {
+			return Vec2{}, false
+		}
+		return pointAlong(origin, dir, fmax(fmin(ta, tb), 0)), true
+	}
+	t := oa.Cross(r) / denom
+	u := oa.Cross(dir) / denom
+	if t < 0 || u < 0 || u > 1 {
+		return Vec2{}, false
+	}
+	return pointAlong(origin, dir, t), true
+}
+func pointAlong(start Vec2, dir Vec2, t float32) Vec2 {
+	return Vec2{start.X() + dir.X()*t, start.Y() + dir.Y()*t}
+}
@@ -0,0 +1,73 @@
+package sysgapp
+
+import "fmt"
+
+// CACHED MESH
+// CachedMesh holds vertices and indexes recorded once by BuildCachedMesh, to be replayed
+// every frame with DrawCachedMesh without tessellating again
+type CachedMesh struct {
+	vertices []meshVertex
+	indexes  []uint16
+}
+type meshVertex struct {
+	pos   Vec2
+	color Color
+	uv    Vec2
+}
+
+// MeshBuilder records geometry with the same calls used to fill the batch. Indexes are local
+// to the mesh, so a mesh holds at most maxBatchVertices vertices to fit in one batch.
+type MeshBuilder struct {
+	mesh *CachedMesh
+}
+
+func BuildCachedMesh(build func(b *MeshBuilder)) *CachedMesh {
+	b := &MeshBuilder{mesh: &CachedMesh{}}
+	build(b)
+	return b.mesh
+}
+func (b *MeshBuilder) AddVertexToBatch(pos Vec2, color *Color, uv Vec2) (index uint16) {
+	if len(b.mesh.vertices) >= maxBatchVertices {
+		panic("sysgapp: cached mesh vertex limit reached, split it into several meshes")
+	}
+	b.mesh.vertices = append(b.mesh.vertices, meshVertex{pos: pos, color: *color, uv: uv})
+	return uint16(len(b.mesh.vertices) - 1)
+}
+
+// AddIndexesToBatch panics on an index past the vertices added so far, catching it while the
+// mesh is built rather than when it is drawn
+func (b *MeshBuilder) AddIndexesToBatch(indexes ...uint16) {
+	for _, idx := range indexes {
+		if int(idx) >= len(b.mesh.vertices) {
+			panic(fmt.Sprintf("sysgapp: cached mesh index %d refers to a vertex never added, the mesh has %d", idx, len(b.mesh.vertices)))
+		}
+	}
+	b.mesh.indexes = append(b.mesh.indexes, indexes...)
+}
+func (m *CachedMesh) VertexCount() int {
+	return len(m.vertices)
+}
+func (m *CachedMesh) IndexCount() int {
+	return len(m.indexes)
+}
+
+// DrawCachedMesh adds the mesh to the batch moved by offset, with tint multiplied into each
+// vertex color. Vertices still pass through the current space's transforms.
+func (s *SystemSolution) DrawCachedMesh(mesh *CachedMesh, offset Vec2, tint *Color) {
+	if mesh == nil || len(mesh.vertices) == 0 {
+		return
+	}
+	idx := s.indexScratch(len(mesh.vertices))
+	s.reserveVertices(len(mesh.vertices))
+	for i := range mesh.vertices {
+		v := &mesh.vertices[i]
+		color := v.color.Multiply(*tint)
+		idx[i] = s.AddVertexToBatch(Vec2{v.pos.X() + offset.X(), v.pos.Y() + offset.Y()}, &color, v.uv)
+	}
+	mapped := s.meshScratch[:0]
+	for _, local := range mesh.indexes {
+		mapped = append(mapped, idx[local])
+	}
+	s.meshScratch = mapped
+	s.AddIndexesToBatch(mapped...)
+}
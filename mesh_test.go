@@ -0,0 +1,41 @@
+package sysgapp
+
+import "testing"
+
+func TestCachedMeshRejectsBadIndex(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("indexing a vertex the mesh never added did not panic")
+		}
+	}()
+	BuildCachedMesh(func(b *MeshBuilder) {
+		a := b.AddVertexToBatch(Vec2{0, 0}, &ColorWhite, Vec2{-1, -1})
+		c := b.AddVertexToBatch(Vec2{1, 0}, &ColorWhite, Vec2{-1, -1})
+		b.AddIndexesToBatch(a, c, c+1)
+	})
+}
+func TestDrawCachedMeshDoesNotAllocate(t *testing.T) {
+	s, lib := newFakeSolution()
+	mesh := BuildCachedMesh(func(b *MeshBuilder) {
+		for i := 0; i < 16; i++ {
+			x := float32(i * 2)
+			tl := b.AddVertexToBatch(Vec2{x, 0}, &ColorWhite, Vec2{-1, -1})
+			tr := b.AddVertexToBatch(Vec2{x + 1, 0}, &ColorWhite, Vec2{-1, -1})
+			br := b.AddVertexToBatch(Vec2{x + 1, 1}, &ColorWhite, Vec2{-1, -1})
+			bl := b.AddVertexToBatch(Vec2{x, 1}, &ColorWhite, Vec2{-1, -1})
+			b.AddIndexesToBatch(bl, tl, br, tl, tr, br)
+		}
+	})
+	s.DrawCachedMesh(mesh, Vec2{10, 10}, &ColorWhite)
+	if lib.BatchVertexCount() != mesh.VertexCount() || lib.BatchIndexCount() != mesh.IndexCount() {
+		t.Fatalf("replayed %d vertices and %d indexes, want %d and %d", lib.BatchVertexCount(), lib.BatchIndexCount(), mesh.VertexCount(), mesh.IndexCount())
+	}
+	s.FlushBatch()
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawCachedMesh(mesh, Vec2{10, 10}, &ColorWhite)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("replaying a cached mesh allocated %v times per call, want 0", allocs)
+	}
+}
@@ -0,0 +1,60 @@
+package sysgapp
+
+// Inset shrinks the rect by margin (left, top, right, bottom), never below zero size
+func (r Rect2D) Inset(margin Vec4) Rect2D {
+	left, top, right, bottom := margin[0], margin[1], margin[2], margin[3]
+	w, h := r.W()-left-right, r.H()-top-bottom
+	if w < 0 {
+		w = 0
+	}
+	if h < 0 {
+		h = 0
+	}
+	return NewRect2D(Vec2{r.X() + left, r.Y() + top}, Vec2{w, h})
+}
+func (r Rect2D) InsetUniform(amount float32) Rect2D {
+	return r.Inset(Vec4{amount, amount, amount, amount})
+}
+
+// Outset grows the rect by margin (left, top, right, bottom)
+func (r Rect2D) Outset(margin Vec4) Rect2D {
+	return r.Inset(Vec4{-margin[0], -margin[1], -margin[2], -margin[3]})
+}
+func (r Rect2D) OutsetUniform(amount float32) Rect2D {
+	return r.Inset(Vec4{-amount, -amount, -amount, -amount})
+}
+
+// Contains reports whether p is inside the rect, including its left and top edges but not its
+// right and bottom edges so a point on the border of two touching rects is only in one of them
+func (r Rect2D) Contains(p Vec2) bool {
+	return p.X() >= r.X() && p.X() < r.X()+r.W() && p.Y() >= r.Y() && p.Y() < r.Y()+r.H()
+}
+
+// Intersect returns the overlap of both rects, or a zero rect and false when they share no area
+func (r Rect2D) Intersect(other Rect2D) (Rect2D, bool) {
+	x, y := fmax(r.X(), other.X()), fmax(r.Y(), other.Y())
+	right := fmin(r.X()+r.W(), other.X()+other.W())
+	bottom := fmin(r.Y()+r.H(), other.Y()+other.H())
+	if right <= x || bottom <= y {
+		return Rect2D{}, false
+	}
+	return NewRect2D(Vec2{x, y}, Vec2{right - x, bottom - y}), true
+}
+func (r Rect2D) Overlaps(other Rect2D) bool {
+	_, overlap := r.Intersect(other)
+	return overlap
+}
+
+// Union returns the smallest rect containing both rects, ignoring either one if it has no area
+func (r Rect2D) Union(other Rect2D) Rect2D {
+	if other.W() <= 0 || other.H() <= 0 {
+		return r
+	}
+	if r.W() <= 0 || r.H() <= 0 {
+		return other
+	}
+	x, y := fmin(r.X(), other.X()), fmin(r.Y(), other.Y())
+	right := fmax(r.X()+r.W(), other.X()+other.W())
+	bottom := fmax(r.Y()+r.H(), other.Y()+other.H())
+	return NewRect2D(Vec2{x, y}, Vec2{right - x, bottom - y})
+}
@@ -0,0 +1,92 @@
+package sysgapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// SPRITE FRAME LOADERS
+// LoadSpriteFramesFromGrid slices columns*rows evenly spaced frames in row order from the top left
+// of a texture. Frames that would reach past the texture's bounds, when its size is known, are
+// logged and left out.
+func (s *SystemSolution) LoadSpriteFramesFromGrid(texIndex TextureIndex, frameSize Vec2, columns int, rows int, drawOffset Vec2) []SpriteFrame {
+	texSize, sized := s.TextureSize(texIndex)
+	frames := make([]SpriteFrame, 0, columns*rows)
+	skipped := 0
+	for row := 0; row < rows; row++ {
+		for col := 0; col < columns; col++ {
+			rect := NewRect2D(Vec2{float32(col) * frameSize.X(), float32(row) * frameSize.Y()}, frameSize)
+			if sized && (rect.X()+rect.W() > texSize.X() || rect.Y()+rect.H() > texSize.Y()) {
+				skipped++
+				continue
+			}
+			frames = append(frames, SpriteFrame{texIndex: texIndex, texRect: rect, drawOffset: drawOffset})
+		}
+	}
+	if skipped > 0 {
+		log.Printf("sysgapp: %d of %d grid frames fall outside texture %d (%vx%v), skipped", skipped, columns*rows, texIndex, texSize.X(), texSize.Y())
+	}
+	return frames
+}
+
+type atlasRect struct {
+	X float32 `json:"x"`
+	Y float32 `json:"y"`
+	W float32 `json:"w"`
+	H float32 `json:"h"`
+}
+type atlasFrame struct {
+	Filename         string    `json:"filename"`
+	Frame            atlasRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize atlasRect `json:"spriteSourceSize"`
+}
+
+// LoadSpriteFramesFromJSON reads TexturePacker style atlas data, either the array form
+// ({"frames": [{"filename": ..., "frame": {...}}]}) in listed order or the hash form
+// ({"frames": {"name": {...}}}) sorted by name. Trimmed frames are offset by their trim.
+func LoadSpriteFramesFromJSON(texIndex TextureIndex, jsonData []byte) ([]SpriteFrame, error) {
+	var doc struct {
+		Frames json.RawMessage `json:"frames"`
+	}
+	if err := json.Unmarshal(jsonData, &doc); err != nil {
+		return nil, fmt.Errorf("sysgapp: parsing sprite atlas: %w", err)
+	}
+	if len(doc.Frames) == 0 {
+		return nil, fmt.Errorf("sysgapp: sprite atlas has no frames")
+	}
+	var list []atlasFrame
+	if err := json.Unmarshal(doc.Frames, &list); err != nil {
+		named := make(map[string]atlasFrame)
+		if err := json.Unmarshal(doc.Frames, &named); err != nil {
+			return nil, fmt.Errorf("sysgapp: sprite atlas frames are neither an array nor an object: %w", err)
+		}
+		for name, frame := range named {
+			frame.Filename = name
+			list = append(list, frame)
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].Filename < list[j].Filename })
+	}
+	frames := make([]SpriteFrame, len(list))
+	for i, f := range list {
+		if f.Rotated {
+			return nil, fmt.Errorf("sysgapp: sprite atlas frame %q is rotated, which is not supported", f.Filename)
+		}
+		if f.Frame.W <= 0 || f.Frame.H <= 0 {
+			return nil, fmt.Errorf("sysgapp: sprite atlas frame %q has no size", f.Filename)
+		}
+		var offset Vec2
+		if f.Trimmed {
+			offset = Vec2{f.SpriteSourceSize.X, f.SpriteSourceSize.Y}
+		}
+		frames[i] = SpriteFrame{
+			texIndex:   texIndex,
+			texRect:    NewRect2D(Vec2{f.Frame.X, f.Frame.Y}, Vec2{f.Frame.W, f.Frame.H}),
+			drawOffset: offset,
+		}
+	}
+	return frames, nil
+}
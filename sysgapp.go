@@ -1,7 +1,15 @@
 package sysgapp
 
 import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math"
+	"os"
 	"sync"
+	"time"
 	"unicode"
 
 	V "github.com/gabe-lee/genvecs"
@@ -29,6 +37,35 @@ const (
 	ComputeShader
 )
 
+func (t ShaderType) String() string {
+	switch t {
+	case VertexShader:
+		return "vertex shader"
+	case FragmentShader:
+		return "fragment shader"
+	case GeometryShader:
+		return "geometry shader"
+	case ComputeShader:
+		return "compute shader"
+	}
+	return fmt.Sprintf("shader type %d", uint8(t))
+}
+
+// ShaderError carries the driver's info log for a shader that failed to compile, or for a
+// render pipe whose shaders compiled but failed to link (Link == true)
+type ShaderError struct {
+	Type ShaderType
+	Link bool
+	Log  string
+}
+
+func (e *ShaderError) Error() string {
+	if e.Link {
+		return "sysgapp: render pipe failed to link:\n" + e.Log
+	}
+	return "sysgapp: " + e.Type.String() + " failed to compile:\n" + e.Log
+}
+
 type Shader struct {
 	sType ShaderType
 	code  string
@@ -48,6 +85,8 @@ const (
 	PNG ImageType = iota
 	BMP
 	WEBP
+	JPEG
+	RawRGBA // Tightly packed 8-bit RGBA pixels matching the texture size, uploaded without decoding
 )
 
 type Texture struct {
@@ -68,6 +107,19 @@ func NewTexture(data []byte, imgType ImageType, size V.F32Vec2, mipMaps int32) *
 	}
 }
 
+func (t *Texture) Size() V.F32Vec2 {
+	return t.size
+}
+func (t *Texture) Type() ImageType {
+	return t.imgType
+}
+func (t *Texture) MipMapLevels() int32 {
+	return t.mipMaps
+}
+func (t *Texture) Dimensions() (w, h int) {
+	return int(t.size.X()), int(t.size.Y())
+}
+
 // var PlanetSweeperTex = NewTexture(PlanetSweeperTexWEBP, WEBP, V.F32Vec2{512, 1024}, 0)
 
 type TextureIndex int
@@ -106,10 +158,68 @@ const (
 	Textured2DVariableColor
 ) // Render Pipe Indexes
 
+type DrawSpace uint8
+
+const (
+	WorldSpace  DrawSpace = iota // Positions pass through the active view transforms
+	ScreenSpace                  // Positions are literal window pixels, ignoring view transforms
+	DesignSpace                  // Positions are in design resolution units, ignoring the camera and pushed transforms
+) // Draw Spaces
+
+type ScaleMode uint8
+
+const (
+	ScaleStretch      ScaleMode = iota // Design space is stretched to fill the window on each axis
+	ScaleLetterbox                     // Design space keeps its aspect, centered with black bars filling the rest
+	ScaleIntegerPixel                  // Like ScaleLetterbox, but scaled by a whole number so pixels stay square and sharp
+) // Scale Modes
+
+type MissingGlyphMode uint8
+
+const (
+	MissingGlyphBox   MissingGlyphMode = iota // Draw a filled box the size of a space
+	MissingGlyphBlank                         // Advance by a box width without drawing
+	MissingGlyphHex                           // Draw the codepoint in hex inside an outlined box
+	MissingGlyphSkip                          // Draw nothing and don't advance, as if the rune wasn't in the text
+) // Missing Glyph Modes
+
+type BlendMode uint8
+
+const (
+	BlendNone     BlendMode = iota // Source color replaces the destination
+	BlendAlpha                     // Source is mixed over the destination by its alpha
+	BlendAdditive                  // Source color times its alpha is added to the destination, for glows and light
+	BlendMultiply                  // Destination is multiplied by the source color, for shadows and tinting
+) // Blend Modes
+
+type LineCap uint8
+
+const (
+	CapButt   LineCap = iota // Line ends flat at its end points
+	CapRound                 // Line ends in a half circle centered on each end point
+	CapSquare                // Line ends flat, extended past each end point by half its thickness
+) // Line Caps
+
+type TextAlign uint8
+
+const (
+	AlignLeft   TextAlign = iota // Lines start at pos.X
+	AlignCenter                  // Lines are centered on pos.X
+	AlignRight                   // Lines end at pos.X
+) // Text Alignments
+
+type TextStyle uint8
+
+const (
+	StyleUnderline     TextStyle = 1 << iota // Line under the glyphs of each line of text
+	StyleStrikethrough                       // Line through the middle of the glyphs of each line of text
+) // Text Styles
+
 // RENDER SURFACE
 type RenderSurface struct {
 	sID  uint32
 	tID  uint32
+	tIDs []uint32
 	size Vec2
 }
 
@@ -121,26 +231,113 @@ func NewRenderSurface(sID uint32, tID uint32, size Vec2) *RenderSurface {
 	}
 }
 
+var ErrNoAttachments = errors.New("sysgapp: multiple render target surface needs at least one attachment")
+
+// NewRenderSurfaceMRT describes a surface with one color attachment per texture,
+// the first of which is also its primary texture
+func NewRenderSurfaceMRT(sID uint32, tIDs []uint32, size Vec2) (*RenderSurface, error) {
+	if len(tIDs) == 0 {
+		return nil, ErrNoAttachments
+	}
+	return &RenderSurface{
+		sID:  sID,
+		tID:  tIDs[0],
+		tIDs: tIDs,
+		size: size,
+	}, nil
+}
+
+type CursorMode uint8
+
+const (
+	CursorNormal   CursorMode = iota // Visible and free to leave the window
+	CursorHidden                     // Hidden while over the window
+	CursorCaptured                   // Hidden and locked to the window, mouse position reports relative motion
+) // Cursor Modes
+
+// TOUCH
+// A Touch is one finger contact; its ID stays the same from begin to end of that contact
+type Touch struct {
+	ID       int64
+	Pos      Vec2
+	Pressure float32
+}
+
+// RENDER STATS
+// RenderStats counts the work submitted to the backend, see LastFrameStats and TotalStats
+type RenderStats struct {
+	DrawCalls       int // Non-empty batch flushes
+	Vertices        int
+	Indices         int
+	TextureBinds    int // Batch texture changes
+	SurfaceSwitches int // Render target binds, including rebinding the outer target after nested draws and blits
+}
+
+func (r *RenderStats) add(other RenderStats) {
+	r.DrawCalls += other.DrawCalls
+	r.Vertices += other.Vertices
+	r.Indices += other.Indices
+	r.TextureBinds += other.TextureBinds
+	r.SurfaceSwitches += other.SurfaceSwitches
+}
+
 type GraphicsInterface interface {
 	Init()
 	Run(func())
 	Teardown()
 	GetWindowSize() V.F32Vec2
-	AddRenderPipe(rendIndex RenderIndex, vShader *Shader, fShader *Shader)
+	SetVSync(enabled bool)
+	IsVSyncEnabled() bool
+	// Returns a *ShaderError holding the compile or link log when the pipe can't be built
+	AddRenderPipeChecked(rendIndex RenderIndex, vShader *Shader, fShader *Shader) error
+	// Shared uniforms live in one uniform block bound to every render pipe
+	SetSharedUniformMatrix(name string, m [16]float32)
 	AddTexture(texIndex TextureIndex, texture *Texture)
 	AddRenderSurface(surfIndex SurfaceIndex, texIndex TextureIndex, size Vec2)
+	// Fragment shaders drawing to an MRT surface write output N to texIndexes[N],
+	// eg. `layout(location = 1) out vec4 mask;` targets texIndexes[1]
+	AddRenderSurfaceMRT(surfIndex SurfaceIndex, texIndexes []TextureIndex, size Vec2)
 	ClearSurface(baseColor *Color)
 	ClearSurfaceArea(surfIndex SurfaceIndex, baseColor *Color, rect Rect2D)
+	// Tightly packed RGBA rows from the top of the surface, errors if surfIndex was never added
+	ReadSurfacePixels(surfIndex SurfaceIndex) ([]byte, Vec2, error)
 
 	DrawBatchIndexedTriangles2D()
 	AddVertexToBatch(pos Vec2, color *Color, uv Vec2) (index uint16)
 	AddIndexesToBatch(indexes ...uint16)
-	//DrawPrimitiveVertexArray2D(verts []Vec2, color *Color, mode VertexMode)
+	// 32-bit index path, only used while SetIndexWidth(32) is active
+	SetIndexWidth(width int)
+	AddVertexToBatch32(pos Vec2, color *Color, uv Vec2) (index uint32)
+	AddIndexesToBatch32(indexes ...uint32)
+	// Counts of vertices and indexes queued since the last DrawBatchIndexedTriangles2D
+	BatchVertexCount() int
+	BatchIndexCount() int
+	SetAlphaPremultiplied(premult bool)
+	SetBlendMode(mode BlendMode)
+	SetBatchTexture(texIndex TextureIndex)
+	// Draws verts immediately with mode, untextured and outside the indexed triangle batch
+	DrawPrimitiveVertexArray2D(verts []Vec2, color *Color, mode VertexMode)
 	//DrawTexturedVertexArray2D(texIndex TextureIndex, destVerts []Vec2, sourceVerts []Vec2, color *Color, mode VertexMode, blendAlpha bool)
 	// Drawing modes
 	DrawToScreen(op func())
 	DrawToSurface(surfIndex SurfaceIndex, op func())
-	//DrawUsingRenderPipe(rendIndex RenderIndex, op func())
+	DrawUsingRenderPipe(rendIndex RenderIndex, op func())
+	// Rebind a target without running an op, to resume an outer DrawToScreen or DrawToSurface after a nested one
+	RestoreScreen()
+	RestoreSurface(surfIndex SurfaceIndex)
+	// Draws srcRect of src's backing texture into dstRect of dst, src and dst must differ
+	BlitSurface(src SurfaceIndex, dst SurfaceIndex, srcRect Rect2D, dstRect Rect2D, color *Color) error
+	// Clipping, applied to batches flushed while the rect is on top of the stack
+	PushClipRect(rect Rect2D)
+	PopClipRect()
+	// Stencil masking, applied to batches flushed until the next stencil call. Writes set the stencil
+	// to level where it is level-1 without drawing color, erases set it back to level-1 where it is
+	// level, and tests draw only where it is at least level.
+	ClearStencil()
+	BeginStencilWrite(level uint8)
+	BeginStencilErase(level uint8)
+	BeginStencilTest(level uint8)
+	EndStencil()
 }
 
 type InputInterface interface {
@@ -152,49 +349,286 @@ type InputInterface interface {
 	SetCallbackOnMouseWheelScroll(op func(offset Vec2))
 	SetCallbackOnMouseMove(op func(pos Vec2))
 	SetCallbackOnMouseButton(op func(button MouseButton, state InputState))
+	// Cursor, in CursorCaptured mode GetMousePosition and mouse move callbacks report movement since the last frame
+	SetCursorVisible(visible bool)
+	SetCursorMode(mode CursorMode)
+	SetCustomCursor(tex *Texture, hotspot Vec2) // nil restores the system cursor
 	// Keyboard Input
 	GetKeyboardKeyState(key KeyboardKey) InputState
+	GetModifierState() KeyboardMod
 	SetCallbackOnRuneInput(op func(r rune))
 	SetCallbackOnKeyPress(op func(key KeyboardKey, state InputState, mods KeyboardMod))
 	// Touch Input
-	//// TODO:
+	GetActiveTouches() []Touch
+	SetCallbackOnTouchBegin(op func(touch Touch))
+	SetCallbackOnTouchMove(op func(touch Touch))
+	SetCallbackOnTouchEnd(op func(touch Touch))
+	// Window Events
+	SetCallbackOnWindowResize(op func(newSize Vec2))
 	// Controller Input
 	//// TODO:
 }
 
 type SystemSolution struct {
-	lib   GraphicsInterface
-	fonts map[FontIndex]*QuadPolyFont
-	lock  *sync.Mutex
+	lib            GraphicsInterface
+	fonts          map[FontIndex]*QuadPolyFont
+	bitmapFonts    map[FontIndex]*bitmapFont
+	defaultFont    FontIndex
+	hasDefault     bool
+	quietFonts     bool
+	warnedFonts    map[FontIndex]bool
+	dynamicIndexes int
+	frameTime      time.Time
+	startTime      time.Time
+	deltaTime      float32
+	frameCount     uint64
+	targetFrame    time.Duration
+	mouseDown      map[MouseButton]time.Time
+	onMouseButton  func(button MouseButton, state InputState)
+	mousePrev      map[MouseButton]bool
+	onMouseMove    func(pos Vec2)
+	gestures       map[MouseButton]*buttonGesture
+	onMouseDrag    func(start Vec2, current Vec2, delta Vec2, button MouseButton)
+	onMouseClick   func(pos Vec2, button MouseButton)
+	onDoubleClick  func(pos Vec2, button MouseButton)
+	dragDist       float32
+	clickWindow    time.Duration
+	keysDown       map[KeyboardKey]bool
+	keysPrev       map[KeyboardKey]bool
+	onKeyPress     func(key KeyboardKey, state InputState, mods KeyboardMod)
+	premultAlpha   bool
+	blend          BlendMode
+	space          DrawSpace
+	batchTexture   TextureIndex
+	batchTexSet    bool
+	texGrouping    bool
+	texGroups      map[TextureIndex][]texQuad
+	texGroupOrder  []TextureIndex
+	frameStats     RenderStats
+	lastStats      RenderStats
+	totalStats     RenderStats
+	flushWarnAt    int
+	missingGlyph   MissingGlyphMode
+	fontMissing    map[FontIndex]MissingGlyphMode
+	hexDigits      bool
+	textTurn       *glyphTurn
+	kerning        map[FontIndex]KerningTable
+	tabWidths      map[FontIndex]float32
+	fallbacks      map[FontIndex]FontIndex
+	surfTextures   map[SurfaceIndex][]TextureIndex
+	texSizes       map[TextureIndex]Vec2
+	scratchIdx     []uint16
+	indexCopy      []uint16
+	wideScratch    []uint32
+	meshScratch    []uint16
+	primScratch    []Vec2
+	pathScratch    []Vec2
+	dirScratch     []Vec2
+	captures       map[TextureIndex]capturedSurface
+	captureFree    []capturedSurface
+	targets        []drawTarget
+	autoResize     map[SurfaceIndex]bool
+	onWindowResize func(newSize Vec2)
+	texRedirects   map[TextureIndex]TextureIndex
+	frameInsets    map[SpriteFrame]Rect2D
+	streamLock     *sync.Mutex
+	streamed       []streamedTexture
+	miterLimit     float32
+	clipRects      []Rect2D
+	maskDepth      uint8
+	opacities      []float32
+	fadeScratch    Color
+	validateBatch  bool
+	onBatchError   func(err error)
+	batchGen       uint32 // Counts flushes, for telling indexes from before one apart
+	staleGen       uint32
+	staleFrom      int
+	staleTo        int
+	layerOps       []layeredOp
+	layerReplay    bool
+	indexedUpTo    int
+	indexWidth     int
+	indexBase      int
+	camera         Affine2D
+	cameraActive   bool
+	camOffset      Vec2
+	camZoom        float32
+	camRotation    float32
+	scrollZoom     *scrollZoom
+	onMouseScroll  func(offset Vec2)
+	transforms     []Affine2D
+	view           Affine2D
+	viewActive     bool
+	design         Affine2D
+	designActive   bool
+	designSize     Vec2
+	designMode     ScaleMode
+	designViewport Rect2D
+	pixelSnap      bool
+	lock           *sync.Mutex
 }
 
+// Surface and texture indexes at or above this value are handed out internally
+const dynamicIndexStart = 1 << 16
+
 var App *SystemSolution
 
 func NewSystemSolution(lib GraphicsInterface) *SystemSolution {
 	return &SystemSolution{
-		lib:  lib,
-		lock: &sync.Mutex{},
+		lib:          lib,
+		blend:        BlendAlpha,
+		indexWidth:   16,
+		mouseDown:    make(map[MouseButton]time.Time),
+		mousePrev:    make(map[MouseButton]bool),
+		gestures:     make(map[MouseButton]*buttonGesture),
+		dragDist:     4,
+		clickWindow:  400 * time.Millisecond,
+		keysDown:     make(map[KeyboardKey]bool),
+		keysPrev:     make(map[KeyboardKey]bool),
+		surfTextures: make(map[SurfaceIndex][]TextureIndex),
+		texSizes:     make(map[TextureIndex]Vec2),
+		autoResize:   make(map[SurfaceIndex]bool),
+		streamLock:   &sync.Mutex{},
+		lock:         &sync.Mutex{},
 	}
 }
 
 // Lifetime
+type InitOptions struct {
+	BuiltinFonts   bool                        // Build and register the PlaniTech solid, outline, and shadow fonts
+	Fonts          map[FontIndex]*QuadPolyFont // Registered after the builtin fonts, replacing any at the same index
+	DefaultFont    FontIndex                   // Passed to SetDefaultFont when UseDefaultFont is set
+	UseDefaultFont bool
+}
+
+// DefaultInitOptions returns the options Init uses, building the builtin fonts without a default font
+func DefaultInitOptions() InitOptions {
+	return InitOptions{BuiltinFonts: true}
+}
 func (s *SystemSolution) Init() {
+	s.InitWithOptions(DefaultInitOptions())
+}
+func (s *SystemSolution) InitWithOptions(opts InitOptions) {
 	s.lib.Init()
+	s.lib.SetCallbackOnMouseButton(s.handleMouseButton)
+	s.lib.SetCallbackOnMouseMove(s.handleMouseMove)
+	s.lib.SetCallbackOnMouseWheelScroll(s.handleMouseScroll)
+	s.lib.SetCallbackOnKeyPress(s.handleKeyPress)
+	s.lib.SetCallbackOnWindowResize(s.handleWindowResize)
 	s.fonts = make(map[FontIndex]*QuadPolyFont)
-	s.AddFont(PlaniTechFontSolid, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 3.5, 0, 8, 18))
-	s.AddFont(PlaniTechFontOutline, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 7, 0, 8, 18))
-	s.AddFont(PlaniTechFontShadow, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 9, 0, 8, 18))
+	if opts.BuiltinFonts {
+		s.AddFont(PlaniTechFontSolid, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 3.5, 0, 8, 18))
+		s.AddFont(PlaniTechFontOutline, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 7, 0, 8, 18))
+		s.AddFont(PlaniTechFontShadow, BuildQuadPolyFont(PlaniTechVBuilder, Vec2{20, 34}, 9, 0, 8, 18))
+	}
+	for fontIndex, font := range opts.Fonts {
+		s.AddFont(fontIndex, font)
+	}
+	if opts.UseDefaultFont {
+		s.SetDefaultFont(opts.DefaultFont)
+	}
 }
 func (s *SystemSolution) Run(op func()) {
-	s.lib.Run(op)
+	s.lib.Run(func() {
+		s.beginFrame()
+		op()
+		s.endFrame()
+	})
+}
+func (s *SystemSolution) beginFrame() {
+	now := time.Now()
+	if s.frameCount == 0 {
+		s.startTime = now
+		s.deltaTime = 0
+	} else {
+		s.deltaTime = float32(now.Sub(s.frameTime).Seconds())
+	}
+	s.frameTime = now
+	s.frameCount++
+	s.frameStats = RenderStats{}
+	s.uploadStreamedTextures()
+}
+func (s *SystemSolution) endFrame() {
+	s.flushLayers()
+	s.flushTextureGroups()
+	s.snapshotInput()
+	s.lastStats = s.frameStats
+	s.totalStats.add(s.frameStats)
+	s.frameStats = RenderStats{}
+	if s.targetFrame > 0 {
+		if remaining := s.targetFrame - time.Since(s.frameTime); remaining > 0 {
+			time.Sleep(remaining)
+		}
+	}
+	if s.flushWarnAt > 0 && s.lastStats.DrawCalls > s.flushWarnAt {
+		log.Printf("sysgapp: %d draw calls this frame exceeds the warning threshold of %d (usually caused by switching textures, blend modes, or render pipes between draws)", s.lastStats.DrawCalls, s.flushWarnAt)
+	}
+}
+
+// Seconds elapsed between the start of the previous frame and the current one, 0 on the first frame
+func (s *SystemSolution) DeltaTime() float32 {
+	return s.deltaTime
+}
+
+// Seconds elapsed between the start of the first frame and the current one
+func (s *SystemSolution) TotalTime() float32 {
+	return float32(s.frameTime.Sub(s.startTime).Seconds())
+}
+func (s *SystemSolution) FrameCount() uint64 {
+	return s.frameCount
+}
+
+// LastFrameStats returns the counts for the most recently completed Run frame
+func (s *SystemSolution) LastFrameStats() RenderStats {
+	return s.lastStats
+}
+
+// TotalStats returns the counts accumulated since Init, including the frame in progress
+func (s *SystemSolution) TotalStats() RenderStats {
+	total := s.totalStats
+	total.add(s.frameStats)
+	return total
+}
+
+// SetTargetFPS sleeps at the end of each frame as needed to cap the Run loop at fps, fps <= 0 is uncapped.
+// With vsync on the loop is already capped to the display refresh rate, so only a target below that has an effect.
+func (s *SystemSolution) SetTargetFPS(fps int) {
+	if fps <= 0 {
+		s.targetFrame = 0
+		return
+	}
+	s.targetFrame = time.Second / time.Duration(fps)
+}
+
+// SetVSync waits for the display refresh before presenting each frame, turn it off to measure raw throughput
+func (s *SystemSolution) SetVSync(enabled bool) {
+	s.lib.SetVSync(enabled)
+}
+func (s *SystemSolution) IsVSyncEnabled() bool {
+	return s.lib.IsVSyncEnabled()
+}
+
+// SetDrawCallWarningThreshold logs once per frame when more than n draw calls are made, n <= 0 disables the warning.
+// Only flushes that submit triangles count, like RenderStats.DrawCalls, not flushes of an empty batch.
+func (s *SystemSolution) SetDrawCallWarningThreshold(n int) {
+	s.flushWarnAt = n
 }
 func (s *SystemSolution) Teardown() {
 	s.lib.Teardown()
 }
+
+// ObtainLock runs op while holding the solution's mutex, releasing it even if op panics
 func (s *SystemSolution) ObtainLock(op func()) {
 	s.lock.Lock()
+	defer s.lock.Unlock()
 	op()
-	s.lock.Unlock()
+}
+
+// DrawLocked is ObtainLock under a name for draw code. Batch and Draw* methods don't lock on
+// their own, so goroutines sharing a SystemSolution must wrap every sequence of draws in
+// DrawLocked to keep their vertices and indexes from interleaving.
+func (s *SystemSolution) DrawLocked(op func()) {
+	s.ObtainLock(op)
 }
 
 // Tools
@@ -208,134 +642,1672 @@ func (s *SystemSolution) GetWindowSize() Vec2 {
 	return s.lib.GetWindowSize()
 }
 
+// Backend returns the GraphicsInterface the SystemSolution was built with. Reaching past
+// SystemSolution's own methods skips its batching, stats, and state tracking, and code that
+// relies on one backend's extra methods won't run on any other.
+func (s *SystemSolution) Backend() GraphicsInterface {
+	return s.lib
+}
+
+// BackendAs returns s's backend as T, for methods a particular backend has beyond GraphicsInterface.
+// T can be the backend's concrete type or an interface listing only the extra methods needed.
+// Methods can't take type parameters, so this is a function rather than a SystemSolution method.
+func BackendAs[T any](s *SystemSolution) (T, bool) {
+	backend, ok := s.lib.(T)
+	return backend, ok
+}
+
 // Asset Linking
+// AddRenderPipe logs shader compile and link errors, use AddRenderPipeChecked to handle them
 func (s *SystemSolution) AddRenderPipe(pIndex RenderIndex, vShader *Shader, fShader *Shader) {
-	s.lib.AddRenderPipe(pIndex, vShader, fShader)
+	if err := s.AddRenderPipeChecked(pIndex, vShader, fShader); err != nil {
+		log.Print(err)
+	}
+}
+func (s *SystemSolution) AddRenderPipeChecked(pIndex RenderIndex, vShader *Shader, fShader *Shader) error {
+	return s.lib.AddRenderPipeChecked(pIndex, vShader, fShader)
 }
+func (s *SystemSolution) SetSharedUniformMatrix(name string, m [16]float32) {
+	s.lib.SetSharedUniformMatrix(name, m)
+}
+
+// AddTexture logs and skips textures whose data can't match their size, see AddTextureFromBytes for an error return
 func (s *SystemSolution) AddTexture(index TextureIndex, texture *Texture) {
+	if err := texture.validate(); err != nil {
+		log.Printf("%v, texture %d not added", err, index)
+		return
+	}
+	s.texSizes[index] = Vec2{texture.size.X(), texture.size.Y()}
 	s.lib.AddTexture(index, texture)
 }
+
+// TextureSize returns the pixel size of a texture or render surface texture added through this SystemSolution
+func (s *SystemSolution) TextureSize(texIndex TextureIndex) (Vec2, bool) {
+	size, ok := s.texSizes[texIndex]
+	return size, ok
+}
+
+// AddTextureFromBytes reads the texture size from the image header instead of requiring the caller to pass it
+func (s *SystemSolution) AddTextureFromBytes(texIndex TextureIndex, data []byte, imgType ImageType) (*Texture, error) {
+	size, err := imageSize(data, imgType)
+	if err != nil {
+		return nil, err
+	}
+	texture := NewTexture(data, imgType, size, 0)
+	s.texSizes[texIndex] = Vec2{size.X(), size.Y()}
+	s.lib.AddTexture(texIndex, texture)
+	return texture, nil
+}
+
+// ErrNoStreamedTexture is passed to onDone when a streaming loader returns neither a texture nor an error
+var ErrNoStreamedTexture = errors.New("sysgapp: streamed texture loader returned no texture")
+
+// AddTextureStreamed runs loader on a worker goroutine, drawing placeholder in place of
+// texIndex until the loaded texture is uploaded at the start of a later frame. onDone,
+// if not nil, is called on the render thread once loading succeeds or fails; on failure
+// the placeholder remains in use.
+func (s *SystemSolution) AddTextureStreamed(texIndex TextureIndex, loader func() (*Texture, error), placeholder TextureIndex, onDone func(texIndex TextureIndex, err error)) {
+	if s.texRedirects == nil {
+		s.texRedirects = make(map[TextureIndex]TextureIndex)
+	}
+	s.texRedirects[texIndex] = placeholder
+	go func() {
+		texture, err := loader()
+		if err == nil && texture == nil {
+			err = ErrNoStreamedTexture
+		}
+		if err == nil {
+			err = texture.validate()
+		}
+		s.streamLock.Lock()
+		s.streamed = append(s.streamed, streamedTexture{texIndex, texture, err, onDone})
+		s.streamLock.Unlock()
+	}()
+}
+
+type streamedTexture struct {
+	texIndex TextureIndex
+	texture  *Texture
+	err      error
+	onDone   func(texIndex TextureIndex, err error)
+}
+
+func (s *SystemSolution) uploadStreamedTextures() {
+	s.streamLock.Lock()
+	done := s.streamed
+	s.streamed = nil
+	s.streamLock.Unlock()
+	for _, st := range done {
+		if st.err == nil {
+			s.AddTexture(st.texIndex, st.texture)
+			delete(s.texRedirects, st.texIndex)
+		}
+		if st.onDone != nil {
+			st.onDone(st.texIndex, st.err)
+		}
+	}
+}
+func (s *SystemSolution) resolveTexture(texIndex TextureIndex) TextureIndex {
+	if placeholder, pending := s.texRedirects[texIndex]; pending {
+		return placeholder
+	}
+	return texIndex
+}
 func (s *SystemSolution) AddRenderSurface(surfIndex SurfaceIndex, texIndex TextureIndex, size Vec2) {
+	s.surfTextures[surfIndex] = []TextureIndex{texIndex}
+	s.texSizes[texIndex] = size
 	s.lib.AddRenderSurface(surfIndex, texIndex, size)
 }
+
+// AddRenderSurfaceMRT returns ErrNoAttachments without adding the surface if texIndexes is empty
+func (s *SystemSolution) AddRenderSurfaceMRT(surfIndex SurfaceIndex, texIndexes []TextureIndex, size Vec2) error {
+	if len(texIndexes) == 0 {
+		return ErrNoAttachments
+	}
+	s.surfTextures[surfIndex] = append([]TextureIndex{}, texIndexes...)
+	for _, texIndex := range texIndexes {
+		s.texSizes[texIndex] = size
+	}
+	s.lib.AddRenderSurfaceMRT(surfIndex, texIndexes, size)
+	return nil
+}
+
+// SetSurfaceAutoResize recreates the surface's backing textures at the window size whenever the window resizes
+func (s *SystemSolution) SetSurfaceAutoResize(surfIndex SurfaceIndex, matchWindow bool) {
+	if matchWindow {
+		s.autoResize[surfIndex] = true
+	} else {
+		delete(s.autoResize, surfIndex)
+	}
+}
+func (s *SystemSolution) SetCallbackOnWindowResize(op func(newSize Vec2)) {
+	s.onWindowResize = op
+}
+func (s *SystemSolution) handleWindowResize(newSize Vec2) {
+	if s.designActive {
+		s.updateDesign(newSize)
+	}
+	if len(s.autoResize) > 0 {
+		// Queued vertices may target a surface about to be replaced
+		s.DrawBatchIndexedTriangles2D()
+	}
+	for surfIndex := range s.autoResize {
+		texIndexes, added := s.surfTextures[surfIndex]
+		for _, texIndex := range texIndexes {
+			s.texSizes[texIndex] = newSize
+		}
+		switch {
+		case !added:
+		case len(texIndexes) == 1:
+			s.lib.AddRenderSurface(surfIndex, texIndexes[0], newSize)
+		default:
+			s.lib.AddRenderSurfaceMRT(surfIndex, texIndexes, newSize)
+		}
+	}
+	if s.onWindowResize != nil {
+		s.onWindowResize(newSize)
+	}
+}
+func (s *SystemSolution) ReadSurfacePixels(surfIndex SurfaceIndex) ([]byte, Vec2, error) {
+	s.DrawBatchIndexedTriangles2D()
+	return s.lib.ReadSurfacePixels(surfIndex)
+}
+func (s *SystemSolution) SaveSurfacePNG(surfIndex SurfaceIndex, path string) error {
+	pixels, size, err := s.ReadSurfacePixels(surfIndex)
+	if err != nil {
+		return err
+	}
+	w, h := int(size.X()), int(size.Y())
+	if len(pixels) != w*h*4 {
+		return fmt.Errorf("sysgapp: surface %d returned %d bytes for %dx%d pixels", surfIndex, len(pixels), w, h)
+	}
+	img := &image.NRGBA{Pix: pixels, Stride: w * 4, Rect: image.Rect(0, 0, w, h)}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
 func (s *SystemSolution) AddFont(fontIndex FontIndex, font *QuadPolyFont) {
 	s.fonts[fontIndex] = font
+	delete(s.warnedFonts, fontIndex)
 }
 func (s *SystemSolution) GetFont(fontIndex FontIndex) *QuadPolyFont {
 	return s.fonts[fontIndex]
 }
+func (s *SystemSolution) HasFont(fontIndex FontIndex) bool {
+	_, ok := s.fonts[fontIndex]
+	return ok
+}
+
+// SetDefaultFont draws text given an unregistered FontIndex in fontIndex instead, without one such text is skipped
+func (s *SystemSolution) SetDefaultFont(fontIndex FontIndex) {
+	s.defaultFont, s.hasDefault = fontIndex, true
+}
+func (s *SystemSolution) ClearDefaultFont() {
+	s.hasDefault = false
+}
+
+// SetMissingFontWarnings turns off, or back on, the log line printed the first time each unregistered FontIndex is drawn
+func (s *SystemSolution) SetMissingFontWarnings(enabled bool) {
+	s.quietFonts = !enabled
+}
+
+// resolveFont returns fontIndex if it is registered, otherwise the default font if there is one
+func (s *SystemSolution) resolveFont(fontIndex FontIndex) (FontIndex, bool) {
+	if _, ok := s.fonts[fontIndex]; ok {
+		return fontIndex, true
+	}
+	_, hasDefault := s.fonts[s.defaultFont]
+	hasDefault = hasDefault && s.hasDefault
+	if !s.quietFonts && !s.warnedFonts[fontIndex] {
+		if s.warnedFonts == nil {
+			s.warnedFonts = make(map[FontIndex]bool)
+		}
+		s.warnedFonts[fontIndex] = true
+		if hasDefault {
+			log.Printf("sysgapp: font %d is not registered, drawing font %d instead", fontIndex, s.defaultFont)
+		} else {
+			log.Printf("sysgapp: font %d is not registered, skipping its text", fontIndex)
+		}
+	}
+	if hasDefault {
+		return s.defaultFont, true
+	}
+	return fontIndex, false
+}
+func (s *SystemSolution) nextDynamicIndex() (SurfaceIndex, TextureIndex) {
+	idx := dynamicIndexStart + s.dynamicIndexes
+	s.dynamicIndexes++
+	return SurfaceIndex(idx), TextureIndex(idx)
+}
+
+type capturedSurface struct {
+	surfIndex SurfaceIndex
+	texIndex  TextureIndex
+	size      Vec2
+}
+
+// CaptureToTexture draws op into a cleared transient surface of the given size and returns
+// its texture, which stays valid until ReleaseCapturedTexture hands the surface back for reuse
+func (s *SystemSolution) CaptureToTexture(size Vec2, op func()) TextureIndex {
+	var capture capturedSurface
+	if last := len(s.captureFree) - 1; last >= 0 {
+		capture = s.captureFree[last]
+		s.captureFree = s.captureFree[:last]
+		if capture.size != size {
+			capture.size = size
+			s.AddRenderSurface(capture.surfIndex, capture.texIndex, size)
+		}
+	} else {
+		surfIndex, texIndex := s.nextDynamicIndex()
+		capture = capturedSurface{surfIndex, texIndex, size}
+		s.AddRenderSurface(surfIndex, texIndex, size)
+	}
+	if s.captures == nil {
+		s.captures = make(map[TextureIndex]capturedSurface)
+	}
+	s.captures[capture.texIndex] = capture
+	s.DrawToSurface(capture.surfIndex, func() {
+		s.ClearSurface(&Color{})
+		op()
+		s.DrawBatchIndexedTriangles2D()
+	})
+	return capture.texIndex
+}
+
+// ReleaseCapturedTexture returns a texture from CaptureToTexture to the free list, it must not be drawn afterwards
+func (s *SystemSolution) ReleaseCapturedTexture(texIndex TextureIndex) {
+	capture, ok := s.captures[texIndex]
+	if !ok {
+		return
+	}
+	delete(s.captures, texIndex)
+	s.captureFree = append(s.captureFree, capture)
+}
 
 // Draw Modes
+type drawTarget struct {
+	surfIndex SurfaceIndex
+	screen    bool
+}
+
+// DrawToScreen and DrawToSurface keep a stack of targets, so a nested call draws to its own
+// target and the outer target is rebound, rather than the screen, when the nested op returns
 func (s *SystemSolution) DrawToScreen(op func()) {
-	s.lib.DrawToScreen(op)
+	s.pushDrawTarget(drawTarget{screen: true})
+	s.lib.DrawToScreen(func() {
+		op()
+		s.flushLayers()
+		s.flushTextureGroups()
+		if len(s.targets) == 1 {
+			s.drawLetterboxBars()
+		}
+	})
+	s.popDrawTarget()
 }
 func (s *SystemSolution) DrawToSurface(surfIndex SurfaceIndex, op func()) {
-	s.lib.DrawToSurface(surfIndex, op)
+	s.pushDrawTarget(drawTarget{surfIndex: surfIndex})
+	s.lib.DrawToSurface(surfIndex, func() {
+		op()
+		s.flushLayers()
+		s.flushTextureGroups()
+	})
+	s.popDrawTarget()
 }
 
-//func (s *SystemSolution) DrawUsingRenderPipe(rendIndex RenderIndex, op func()) {
-//	s.lib.DrawUsingRenderPipe(rendIndex, op)
-//}
-// Basic Draw Functions
-func (s *SystemSolution) ClearSurface(baseColor *Color) {
-	s.lib.ClearSurface(baseColor)
+// CurrentDrawTarget returns the surface being drawn to, or toScreen == true inside DrawToScreen.
+// Outside of both, ok is false.
+func (s *SystemSolution) CurrentDrawTarget() (surfIndex SurfaceIndex, toScreen bool, ok bool) {
+	if len(s.targets) == 0 {
+		return 0, false, false
+	}
+	top := s.targets[len(s.targets)-1]
+	return top.surfIndex, top.screen, true
+}
+func (s *SystemSolution) pushDrawTarget(target drawTarget) {
+	if len(s.targets) > 0 {
+		// Queued geometry belongs to the outer target
+		s.flushTextureGroups()
+		s.DrawBatchIndexedTriangles2D()
+	}
+	s.frameStats.SurfaceSwitches++
+	s.targets = append(s.targets, target)
 }
-func (s *SystemSolution) ClearSurfaceArea(surfIndex SurfaceIndex, baseColor *Color, rect Rect2D) {
-	s.lib.ClearSurfaceArea(surfIndex, baseColor, rect)
+func (s *SystemSolution) popDrawTarget() {
+	s.targets = s.targets[:len(s.targets)-1]
+	s.restoreDrawTarget()
 }
-func (s *SystemSolution) DrawBatchIndexedTriangles2D() {
-	s.lib.DrawBatchIndexedTriangles2D()
+func (s *SystemSolution) restoreDrawTarget() {
+	if len(s.targets) == 0 {
+		return
+	}
+	s.frameStats.SurfaceSwitches++
+	if outer := s.targets[len(s.targets)-1]; outer.screen {
+		s.lib.RestoreScreen()
+	} else {
+		s.lib.RestoreSurface(outer.surfIndex)
+	}
 }
-func (s *SystemSolution) AddVertexToBatch(pos Vec2, color *Color, uv Vec2) (index uint16) {
-	return s.lib.AddVertexToBatch(pos, color, uv)
+func (s *SystemSolution) DrawInSpace(space DrawSpace, op func()) {
+	prev := s.space
+	s.space = space
+	op()
+	s.space = prev
 }
-func (s *SystemSolution) AddIndexesToBatch(indexes ...uint16) {
-	s.lib.AddIndexesToBatch(indexes...)
+func (s *SystemSolution) DrawUsingRenderPipe(rendIndex RenderIndex, op func()) {
+	s.lib.DrawUsingRenderPipe(rendIndex, op)
 }
 
-//func (s *SystemSolution) DrawPrimitiveVertexArray2D(verts []Vec2, color *Color, mode VertexMode) {
-//	s.lib.DrawPrimitiveVertexArray2D(verts, color, mode)
-//}
-//func (s *SystemSolution) DrawTexturedVertexArray2D(texIndex TextureIndex, destVerts []Vec2, sourceVerts []Vec2, color *Color, mode VertexMode, blendAlpha bool) {
-//	s.lib.DrawTexturedVertexArray2D(texIndex, destVerts, sourceVerts, color, mode, blendAlpha)
-//}
-// Input Events
-func (s *SystemSolution) GetMouseButtonState(button MouseButton) InputState {
-	return s.lib.GetMouseButtonState(button)
+// Camera and Transforms
+// SetCamera views the world with offset at the top-left of the screen, scaled by zoom and then
+// rotated by rotation radians around that corner. Every vertex drawn in WorldSpace is transformed,
+// so sizes such as line thickness are in world units and scale with zoom; UVs are unaffected.
+func (s *SystemSolution) SetCamera(offset Vec2, zoom float32, rotation float32) {
+	s.camera = Affine2DTranslate(Vec2{-offset.X(), -offset.Y()}).Then(Affine2DScale(Vec2{zoom, zoom})).Then(Affine2DRotate(rotation))
+	s.camOffset, s.camZoom, s.camRotation = offset, zoom, rotation
+	s.cameraActive = true
+	s.updateView()
+}
+func (s *SystemSolution) ResetCamera() {
+	s.camera = Affine2DIdentity
+	s.cameraActive = false
+	s.updateView()
 }
-func (s *SystemSolution) SetCallbackOnMouseWheelScroll(op func(offset Vec2)) {
-	s.lib.SetCallbackOnMouseWheelScroll(op)
+
+// GetCamera returns the values last passed to SetCamera, or an unzoomed camera at the origin after ResetCamera
+func (s *SystemSolution) GetCamera() (offset Vec2, zoom float32, rotation float32) {
+	if !s.cameraActive {
+		return Vec2{}, 1, 0
+	}
+	return s.camOffset, s.camZoom, s.camRotation
 }
-func (s *SystemSolution) GetMousePosition() Vec2 {
-	return s.lib.GetMousePosition()
+
+// Scroll Zoom
+type scrollZoom struct {
+	min      float32
+	max      float32
+	toCursor bool
 }
-func (s *SystemSolution) GetKeyboardKeyState(key KeyboardKey) InputState {
-	return s.lib.GetKeyboardKeyState(key)
+
+// Each notch of vertical scroll multiplies the camera zoom by this factor
+const scrollZoomStep = 1.1
+
+// EnableScrollZoom zooms the camera with the mouse wheel, clamped to [min, max]. With zoomToCursor
+// the world point under the cursor stays put, otherwise the camera offset is kept. Callbacks set with
+// SetCallbackOnMouseWheelScroll still run after the zoom is applied.
+func (s *SystemSolution) EnableScrollZoom(min float32, max float32, zoomToCursor bool) {
+	if min > max {
+		min, max = max, min
+	}
+	s.scrollZoom = &scrollZoom{min: min, max: max, toCursor: zoomToCursor}
 }
-func (s *SystemSolution) SetCallbackOnRuneInput(op func(r rune)) {
-	s.lib.SetCallbackOnRuneInput(op)
+func (s *SystemSolution) DisableScrollZoom() {
+	s.scrollZoom = nil
 }
-func (s *SystemSolution) SetCallbackOnKeyPress(op func(key KeyboardKey, state InputState, mods KeyboardMod)) {
-	s.lib.SetCallbackOnKeyPress(op)
+func (s *SystemSolution) applyScrollZoom(scroll Vec2) {
+	if scroll.Y() == 0 {
+		return
+	}
+	offset, zoom, rotation := s.GetCamera()
+	next := zoom * float32(math.Pow(scrollZoomStep, float64(scroll.Y())))
+	if next < s.scrollZoom.min {
+		next = s.scrollZoom.min
+	}
+	if next > s.scrollZoom.max {
+		next = s.scrollZoom.max
+	}
+	if next == zoom {
+		return
+	}
+	if s.scrollZoom.toCursor {
+		// The camera maps p to rotate((p - offset) * zoom), keep that equal for the point under the cursor
+		anchor := s.ScreenToWorld(s.GetMousePosition())
+		ratio := zoom / next
+		offset = Vec2{anchor.X() - (anchor.X()-offset.X())*ratio, anchor.Y() - (anchor.Y()-offset.Y())*ratio}
+	}
+	s.SetCamera(offset, next, rotation)
 }
-func (s *SystemSolution) SetCallbackOnMouseMove(op func(pos Vec2)) {
-	s.lib.SetCallbackOnMouseMove(op)
+
+// PushTransform makes subsequent world-space draws local to a frame scaled by scale, rotated by
+// rotate radians, and then moved by translate, nested inside any transform already pushed
+func (s *SystemSolution) PushTransform(translate Vec2, rotate float32, scale Vec2) {
+	local := Affine2DScale(scale).Then(Affine2DRotate(rotate)).Then(Affine2DTranslate(translate))
+	if len(s.transforms) > 0 {
+		local = local.Then(s.transforms[len(s.transforms)-1])
+	}
+	s.transforms = append(s.transforms, local)
+	s.updateView()
 }
-func (s *SystemSolution) SetCallbackOnMouseButton(op func(button MouseButton, state InputState)) {
-	s.lib.SetCallbackOnMouseButton(op)
+func (s *SystemSolution) PopTransform() {
+	if len(s.transforms) == 0 {
+		return
+	}
+	s.transforms = s.transforms[:len(s.transforms)-1]
+	s.updateView()
 }
 
-// Advanced Drawing Functions
-//func (s *SystemSolution) DrawPixel2D(pos Vec2, color *Color) {
-//	s.DrawPrimitiveVertexArray2D([]Vec2{pos}, color, Pixels)
-//}
-// Polygons and Circles
-func (s *SystemSolution) DrawRegularPolygon(pos Vec2, count float32, radius float32, color *Color, rotation float32) {
-	count = FFLoor(count)
-	idx := make([]uint16, int(count))
-	points := PointsOnCircle(count, radius, pos, rotation)
-	cen := s.AddVertexToBatch(pos, color, Vec2{-1, -1})
-	for i := range points {
-		idx[i] = s.AddVertexToBatch(points[i], color, Vec2{-1, -1})
-		if i > 0 {
-			s.AddIndexesToBatch(cen, idx[i-1], idx[i])
-		}
+// updateView caches the combined transform stack and camera so each vertex costs one multiply
+func (s *SystemSolution) updateView() {
+	s.view = Affine2DIdentity
+	if len(s.transforms) > 0 {
+		s.view = s.transforms[len(s.transforms)-1]
 	}
-	s.AddIndexesToBatch(cen, idx[len(idx)-1], idx[0])
-}
-func (s *SystemSolution) DrawRegularPolygonRing(pos Vec2, count float32, innerRadius float32, outerRadius float32, color *Color, rotation float32) {
-	count = FFLoor(count)
-	idx := make([]uint16, int(count)*2)
-	points := PointsOnRing(count, innerRadius, outerRadius, pos, rotation)
-	for i := range points {
-		idx[i] = s.AddVertexToBatch(points[i], color, Vec2{-1, -1})
+	if s.cameraActive {
+		s.view = s.view.Then(s.camera)
 	}
-	for i := 0; i <= len(idx)-4; i += 2 {
-		s.AddIndexesToBatch(idx[i+0], idx[i+1], idx[i+2], idx[i+1], idx[i+3], idx[i+2])
+	if s.designActive {
+		s.view = s.view.Then(s.design)
 	}
-	s.AddIndexesToBatch(idx[len(idx)-2], idx[len(idx)-1], idx[0], idx[len(idx)-1], idx[1], idx[0])
+	s.viewActive = s.cameraActive || len(s.transforms) > 0 || s.designActive
 }
-func (s *SystemSolution) DrawCircleAutoPoints(pos Vec2, resolution float32, radius float32, color *Color) {
-	count := Circumference(radius) / resolution
-	s.DrawRegularPolygon(pos, count, radius, color, 0)
+
+// WorldToScreen and ScreenToWorld convert between world positions and window pixels,
+// accounting for the camera and the design resolution's scale and letterbox offset
+func (s *SystemSolution) WorldToScreen(p Vec2) Vec2 {
+	return s.worldToWindow().Apply(p)
+}
+func (s *SystemSolution) ScreenToWorld(p Vec2) Vec2 {
+	return s.worldToWindow().Inverse().Apply(p)
+}
+func (s *SystemSolution) worldToWindow() Affine2D {
+	m := Affine2DIdentity
+	if s.cameraActive {
+		m = s.camera
+	}
+	if s.designActive {
+		m = m.Then(s.design)
+	}
+	return m
 }
-func (s *SystemSolution) DrawCircleRingAutoPoints(pos Vec2, resolution float32, innerRadius float32, outerRadius float32, color *Color) {
-	count := Circumference(outerRadius) / resolution
-	s.DrawRegularPolygonRing(pos, count, innerRadius, outerRadius, color, 0)
+
+// spacePos applies the transform of the current DrawSpace to pos
+func (s *SystemSolution) spacePos(pos Vec2) Vec2 {
+	switch {
+	case s.space == WorldSpace && s.viewActive:
+		return s.view.Apply(pos)
+	case s.space == DesignSpace && s.designActive:
+		return s.design.Apply(pos)
+	}
+	return pos
 }
-func (s *SystemSolution) DrawCircle(pos Vec2, radius float32, color *Color) {
-	s.DrawCircleAutoPoints(pos, 2, radius, color)
+
+// Design Resolution
+// SetDesignResolution draws WorldSpace and DesignSpace in units of size, scaled onto the window by mode.
+// It follows window resizes; ScreenSpace draws are still in window pixels.
+func (s *SystemSolution) SetDesignResolution(size Vec2, mode ScaleMode) {
+	if size.X() <= 0 || size.Y() <= 0 {
+		log.Printf("sysgapp: design resolution %v must be positive, ignoring it", size)
+		return
+	}
+	s.designSize = size
+	s.designMode = mode
+	s.designActive = true
+	s.updateDesign(s.GetWindowSize())
 }
-func (s *SystemSolution) DrawCircleRing(pos Vec2, innerRadius float32, outerRadius float32, color *Color) {
-	s.DrawCircleRingAutoPoints(pos, 2, innerRadius, outerRadius, color)
+func (s *SystemSolution) ClearDesignResolution() {
+	s.designActive = false
+	s.design = Affine2DIdentity
+	s.updateView()
 }
 
-// Rectangles
-func (s *SystemSolution) DrawRect(rect Rect2D, color *Color) {
-	s.DrawRectRotated(rect, color, 0, Vec2{})
+// DesignViewport returns the window pixels covered by design space, the whole window without a design resolution
+func (s *SystemSolution) DesignViewport() Rect2D {
+	if !s.designActive {
+		return NewRect2D(Vec2{}, s.GetWindowSize())
+	}
+	return s.designViewport
+}
+func (s *SystemSolution) updateDesign(window Vec2) {
+	scaleX, scaleY := window.X()/s.designSize.X(), window.Y()/s.designSize.Y()
+	if s.designMode != ScaleStretch {
+		fit := scaleX
+		if scaleY < fit {
+			fit = scaleY
+		}
+		// A window smaller than the design can't fit a whole number scale, fall back to a fractional one
+		if s.designMode == ScaleIntegerPixel && fit >= 1 {
+			fit = FFLoor(fit)
+		}
+		scaleX, scaleY = fit, fit
+	}
+	size := Vec2{s.designSize.X() * scaleX, s.designSize.Y() * scaleY}
+	offset := Vec2{(window.X() - size.X()) / 2, (window.Y() - size.Y()) / 2}
+	if s.designMode == ScaleIntegerPixel {
+		offset = Vec2{FFLoor(offset.X()), FFLoor(offset.Y())}
+	}
+	s.designViewport = NewRect2D(offset, size)
+	s.design = Affine2DScale(Vec2{scaleX, scaleY}).Then(Affine2DTranslate(offset))
+	s.updateView()
 }
-func (s *SystemSolution) DrawRectOutline(rect Rect2D, color *Color, thickness float32) {
-	s.DrawRectOutlineRotated(rect, color, thickness, 0, Vec2{})
+
+// drawLetterboxBars covers the window outside the design viewport in black
+func (s *SystemSolution) drawLetterboxBars() {
+	if !s.designActive || s.designMode == ScaleStretch {
+		return
+	}
+	window := s.GetWindowSize()
+	view := s.designViewport
+	black := Color{0, 0, 0, 1}
+	s.DrawInSpace(ScreenSpace, func() {
+		if view.X() > 0 {
+			s.DrawRect(Rect2D{0, 0, view.X(), window.Y()}, &black)
+			s.DrawRect(Rect2D{view.X() + view.W(), 0, window.X() - view.X() - view.W(), window.Y()}, &black)
+		}
+		if view.Y() > 0 {
+			s.DrawRect(Rect2D{view.X(), 0, view.W(), view.Y()}, &black)
+			s.DrawRect(Rect2D{view.X(), view.Y() + view.H(), view.W(), window.Y() - view.Y() - view.H()}, &black)
+		}
+	})
+}
+
+// Clipping
+func (s *SystemSolution) PushClipRect(rect Rect2D) {
+	if len(s.clipRects) > 0 {
+		rect, _ = s.clipRects[len(s.clipRects)-1].Intersect(rect)
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.clipRects = append(s.clipRects, rect)
+	s.lib.PushClipRect(rect)
+}
+func (s *SystemSolution) PopClipRect() {
+	if len(s.clipRects) == 0 {
+		return
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.clipRects = s.clipRects[:len(s.clipRects)-1]
+	s.lib.PopClipRect()
+}
+func (s *SystemSolution) GetClipRect() (rect Rect2D, active bool) {
+	if len(s.clipRects) == 0 {
+		return Rect2D{}, false
+	}
+	return s.clipRects[len(s.clipRects)-1], true
+}
+
+// Stencil Masking
+// DrawMasked draws contentOp only where maskOp's geometry covers, maskOp draws no color of its own.
+// Calls nest, clipping content to the intersection of every mask around it; a nested mask is
+// drawn a second time on the way out to erase it. Texture grouping is suspended inside both ops.
+func (s *SystemSolution) DrawMasked(maskOp func(), contentOp func()) {
+	if s.maskDepth == math.MaxUint8 {
+		log.Printf("sysgapp: masks nested more than %d deep, drawing content unmasked", math.MaxUint8)
+		contentOp()
+		return
+	}
+	grouping := s.texGrouping
+	s.texGrouping = false
+	s.DrawBatchIndexedTriangles2D()
+	if s.maskDepth == 0 {
+		s.lib.ClearStencil()
+	}
+	s.maskDepth++
+	level := s.maskDepth
+	s.lib.BeginStencilWrite(level)
+	maskOp()
+	s.DrawBatchIndexedTriangles2D()
+	s.lib.BeginStencilTest(level)
+	contentOp()
+	s.DrawBatchIndexedTriangles2D()
+	s.maskDepth--
+	if s.maskDepth == 0 {
+		s.lib.EndStencil()
+	} else {
+		s.lib.BeginStencilErase(level)
+		maskOp()
+		s.DrawBatchIndexedTriangles2D()
+		s.lib.BeginStencilTest(s.maskDepth)
+	}
+	s.texGrouping = grouping
+}
+
+// Basic Draw Functions
+// ClearSurface clears the current draw target, use ClearSurfaceIndex to clear a specific surface
+func (s *SystemSolution) ClearSurface(baseColor *Color) {
+	s.lib.ClearSurface(baseColor)
+}
+
+// ClearSurfaceIndex clears surfIndex whatever the current target is, then rebinds that target
+func (s *SystemSolution) ClearSurfaceIndex(surfIndex SurfaceIndex, baseColor *Color) {
+	s.DrawToSurface(surfIndex, func() {
+		s.lib.ClearSurface(baseColor)
+	})
+}
+func (s *SystemSolution) ClearSurfaceArea(surfIndex SurfaceIndex, baseColor *Color, rect Rect2D) {
+	s.lib.ClearSurfaceArea(surfIndex, baseColor, rect)
+}
+
+var ErrBlitSameSurface = errors.New("sysgapp: cannot blit a surface onto itself")
+
+// BlitSurface flushes the current batch, then composites srcRect of src onto dstRect of dst
+func (s *SystemSolution) BlitSurface(src SurfaceIndex, dst SurfaceIndex, srcRect Rect2D, dstRect Rect2D, color *Color) error {
+	if src == dst {
+		return ErrBlitSameSurface
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.frameStats.SurfaceSwitches++
+	s.frameStats.DrawCalls++
+	err := s.lib.BlitSurface(src, dst, srcRect, dstRect, color)
+	s.restoreDrawTarget()
+	return err
+}
+func (s *SystemSolution) DrawBatchIndexedTriangles2D() {
+	if indices := s.lib.BatchIndexCount(); indices > 0 {
+		s.frameStats.DrawCalls++
+		s.frameStats.Vertices += s.lib.BatchVertexCount()
+		s.frameStats.Indices += indices
+	}
+	if s.validateBatch {
+		s.validateFlush()
+	}
+	s.batchGen++
+	s.indexedUpTo = 0
+	s.indexBase = 0
+	s.lib.DrawBatchIndexedTriangles2D()
+}
+
+// A batch holds at most this many vertices, the range of its uint16 indexes
+const maxBatchVertices = 1 << 16
+
+// With 32-bit indexes a batch holds at most this many vertices
+const maxBatchVertices32 = 1 << 24
+
+// AddVertexToBatch flushes the batch first when it is full. Indexes from before a
+// flush are invalid afterwards, so a full batch may only be flushed once every vertex
+// in it has been referenced by AddIndexesToBatch; running out of room mid-primitive
+// panics. Draw helpers avoid this by reserving their vertex count up front.
+//
+// With 32-bit indexes the returned index is relative to a window of maxBatchVertices
+// vertices that moves forward instead of flushing, so the same rules apply per window
+// while the batch itself grows up to maxBatchVertices32.
+func (s *SystemSolution) AddVertexToBatch(pos Vec2, color *Color, uv Vec2) (index uint16) {
+	pos = s.screenPos(pos)
+	faded := s.fadeColor(color)
+	count := s.lib.BatchVertexCount()
+	if count < s.indexedUpTo {
+		s.indexedUpTo = 0
+		s.indexBase = 0
+	}
+	if s.indexWidth == 32 {
+		if count-s.indexBase >= maxBatchVertices || count >= maxBatchVertices32 {
+			if s.indexedUpTo < count {
+				panic("sysgapp: batch vertex limit reached mid-primitive, reserve room or call FlushBatch before adding its vertices")
+			}
+			s.advanceIndexWindow(count)
+		}
+		return uint16(int(s.lib.AddVertexToBatch32(pos, faded, uv)) - s.indexBase)
+	}
+	if count >= maxBatchVertices {
+		if s.indexedUpTo < count {
+			panic("sysgapp: batch vertex limit reached mid-primitive, reserve room or call FlushBatch before adding its vertices")
+		}
+		s.DrawBatchIndexedTriangles2D()
+	}
+	return s.lib.AddVertexToBatch(pos, faded, uv)
+}
+
+// screenPos applies the current space's transform and pixel snapping, like every batched vertex
+func (s *SystemSolution) screenPos(pos Vec2) Vec2 {
+	pos = s.spacePos(pos)
+	if s.pixelSnap {
+		pos = Vec2{float32(math.Round(float64(pos.X()))), float32(math.Round(float64(pos.Y())))}
+	}
+	return pos
+}
+func (s *SystemSolution) AddIndexesToBatch(indexes ...uint16) {
+	if s.indexWidth == 32 {
+		wide := s.wideScratch[:0]
+		for _, idx := range indexes {
+			wide = append(wide, uint32(s.indexBase+int(idx)))
+		}
+		s.wideScratch = wide
+		s.addIndexes32(wide)
+		return
+	}
+	if s.validateBatch {
+		for _, idx := range indexes {
+			s.validateIndex(int(idx))
+		}
+	}
+	for _, idx := range indexes {
+		if int(idx) >= s.indexedUpTo {
+			s.indexedUpTo = int(idx) + 1
+		}
+	}
+	// Handing the backend a copy keeps indexes from escaping, so callers' variadic arguments stay on the stack
+	s.indexCopy = append(s.indexCopy[:0], indexes...)
+	s.lib.AddIndexesToBatch(s.indexCopy...)
+}
+
+// advanceIndexWindow starts a new uint16 index window at count, flushing instead once
+// the 32-bit batch is full
+func (s *SystemSolution) advanceIndexWindow(count int) {
+	if count >= maxBatchVertices32 {
+		s.DrawBatchIndexedTriangles2D()
+		return
+	}
+	s.indexBase = count
+}
+
+// reserveVertices flushes the batch if it cannot fit n more vertices, keeping a primitive within one batch
+func (s *SystemSolution) reserveVertices(n int) {
+	count := s.lib.BatchVertexCount()
+	if s.indexWidth == 32 {
+		if count < s.indexedUpTo {
+			s.indexedUpTo = 0
+			s.indexBase = 0
+		}
+		if count+n > maxBatchVertices32 {
+			s.DrawBatchIndexedTriangles2D()
+		} else if count-s.indexBase+n > maxBatchVertices {
+			s.indexBase = count
+		}
+		return
+	}
+	if count+n > maxBatchVertices {
+		s.DrawBatchIndexedTriangles2D()
+	}
+}
+
+// SetIndexWidth selects 16 or 32-bit batch indexes, flushing the current batch on change.
+// 32-bit indexes let one batch hold up to maxBatchVertices32 vertices for very large meshes.
+func (s *SystemSolution) SetIndexWidth(width int) {
+	if width != 16 && width != 32 {
+		log.Printf("sysgapp: unsupported index width %d, keeping %d", width, s.indexWidth)
+		return
+	}
+	if width == s.indexWidth {
+		return
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.indexWidth = width
+	s.lib.SetIndexWidth(width)
+}
+func (s *SystemSolution) GetIndexWidth() int {
+	return s.indexWidth
+}
+
+// AddVertexToBatch32 and AddIndexesToBatch32 address the whole batch directly and
+// require SetIndexWidth(32); the batch is flushed once it reaches maxBatchVertices32
+func (s *SystemSolution) AddVertexToBatch32(pos Vec2, color *Color, uv Vec2) (index uint32) {
+	if s.indexWidth != 32 {
+		panic("sysgapp: AddVertexToBatch32 needs SetIndexWidth(32)")
+	}
+	pos = s.screenPos(pos)
+	faded := s.fadeColor(color)
+	count := s.lib.BatchVertexCount()
+	if count < s.indexedUpTo {
+		s.indexedUpTo = 0
+		s.indexBase = 0
+	}
+	if count >= maxBatchVertices32 {
+		if s.indexedUpTo < count {
+			panic("sysgapp: batch vertex limit reached mid-primitive, reserve room or call FlushBatch before adding its vertices")
+		}
+		s.DrawBatchIndexedTriangles2D()
+	}
+	return s.lib.AddVertexToBatch32(pos, faded, uv)
+}
+func (s *SystemSolution) AddIndexesToBatch32(indexes ...uint32) {
+	if s.indexWidth != 32 {
+		panic("sysgapp: AddIndexesToBatch32 needs SetIndexWidth(32)")
+	}
+	// As in AddIndexesToBatch, handing the backend a copy keeps callers' variadic arguments on the stack
+	s.wideScratch = append(s.wideScratch[:0], indexes...)
+	s.addIndexes32(s.wideScratch)
+}
+func (s *SystemSolution) addIndexes32(indexes []uint32) {
+	if s.validateBatch {
+		for _, idx := range indexes {
+			s.validateIndex(int(idx))
+		}
+	}
+	for _, idx := range indexes {
+		if int(idx) >= s.indexedUpTo {
+			s.indexedUpTo = int(idx) + 1
+		}
+	}
+	s.lib.AddIndexesToBatch32(indexes...)
+}
+func (s *SystemSolution) BatchVertexCount() int {
+	return s.lib.BatchVertexCount()
+}
+func (s *SystemSolution) BatchIndexCount() int {
+	return s.lib.BatchIndexCount()
+}
+
+// Batch Validation
+var (
+	ErrBatchIndexRange = errors.New("sysgapp: batch index refers to a vertex that was never added")
+	ErrBatchIndexCount = errors.New("sysgapp: batch index count is not a multiple of 3")
+	ErrBatchStaleIndex = errors.New("sysgapp: batch index was handed out before the last flush")
+	ErrBatchUnindexed  = errors.New("sysgapp: batch flushed with vertices never indexed")
+)
+
+// SetBatchValidation checks batch indexes as they are added and the batch as it is flushed,
+// reporting mistakes to the batch error handler. Each check costs a little per index, so it
+// is off by default and meant for debugging.
+func (s *SystemSolution) SetBatchValidation(enabled bool) {
+	s.validateBatch = enabled
+}
+
+// SetBatchErrorHandler replaces the default handler, which logs, for errors found by batch validation.
+// Errors wrap ErrBatchIndexRange, ErrBatchIndexCount, ErrBatchStaleIndex, or ErrBatchUnindexed.
+func (s *SystemSolution) SetBatchErrorHandler(op func(err error)) {
+	s.onBatchError = op
+}
+func (s *SystemSolution) batchError(err error) {
+	if s.onBatchError != nil {
+		s.onBatchError(err)
+		return
+	}
+	log.Print(err)
+}
+
+// validateIndex reports an index past the vertices in the batch as stale when it falls among
+// those the last flush left unindexed, since whoever added them still holds their old indexes.
+// A stale index that lands on a vertex added since the flush looks valid and isn't caught.
+func (s *SystemSolution) validateIndex(idx int) {
+	count := s.lib.BatchVertexCount()
+	if idx < count {
+		return
+	}
+	if s.staleGen == s.batchGen && idx >= s.staleFrom && idx < s.staleTo {
+		s.batchError(fmt.Errorf("%w: index %d was left unindexed by flush %d", ErrBatchStaleIndex, idx, s.batchGen-1))
+		return
+	}
+	s.batchError(fmt.Errorf("%w: index %d with %d vertices in the batch", ErrBatchIndexRange, idx, count))
+}
+
+// validateFlush reports a partial triangle, and vertices left unindexed whose indexes stop
+// being valid with this flush, usually because a primitive called FlushBatch midway. The
+// unindexed range is kept for the next batch so validateIndex can recognise those indexes.
+func (s *SystemSolution) validateFlush() {
+	if indices := s.lib.BatchIndexCount(); indices%3 != 0 {
+		s.batchError(fmt.Errorf("%w: %d indexes queued", ErrBatchIndexCount, indices))
+	}
+	if count := s.lib.BatchVertexCount(); s.indexedUpTo < count {
+		s.batchError(fmt.Errorf("%w: vertices %d to %d, indexing them after this flush draws the wrong vertices", ErrBatchUnindexed, s.indexedUpTo, count-1))
+		s.staleGen, s.staleFrom, s.staleTo = s.batchGen+1, s.indexedUpTo, count
+	}
+}
+
+// FlushBatch draws everything queued so far and starts a new batch with its counts (and returned vertex indexes) reset to zero
+func (s *SystemSolution) FlushBatch() {
+	s.DrawBatchIndexedTriangles2D()
+}
+func (s *SystemSolution) SetBatchTexture(texIndex TextureIndex) {
+	if s.batchTexSet && texIndex == s.batchTexture {
+		return
+	}
+	s.batchTexture, s.batchTexSet = texIndex, true
+	s.frameStats.TextureBinds++
+	s.lib.SetBatchTexture(texIndex)
+}
+
+// SetBatchTextureGrouping defers textured quads until the end of the current draw
+// target (or frame) and submits them grouped by texture, in order of each texture's
+// first use. Textured quads therefore draw after all untextured geometry and
+// quads from different textures no longer keep their relative order, so only
+// enable it where overlapping draws from different textures don't occur.
+func (s *SystemSolution) SetBatchTextureGrouping(enabled bool) {
+	if !enabled {
+		s.flushTextureGroups()
+	}
+	s.texGrouping = enabled
+}
+
+// DrawTexturedBatch binds texIndex while op runs and flushes everything op queued as one
+// draw call when it returns. Untextured primitives use the {-1, -1} UV, which the shader
+// draws without sampling, so they can be mixed freely with the bound texture's draws.
+// A textured draw from a different texture inside op rebinds the batch texture, which
+// flushes the batch, so texIndex no longer applies for the rest of op. Texture grouping
+// is suspended inside op so its textured quads draw in order with the rest of the batch.
+func (s *SystemSolution) DrawTexturedBatch(texIndex TextureIndex, op func()) {
+	grouping := s.texGrouping
+	s.texGrouping = false
+	s.SetBatchTexture(s.resolveTexture(texIndex))
+	op()
+	s.FlushBatch()
+	s.texGrouping = grouping
+}
+func (s *SystemSolution) flushTextureGroups() {
+	if len(s.texGroupOrder) == 0 {
+		return
+	}
+	// Grouped quads were transformed and faded when recorded, so they replay in screen space at full opacity
+	prevBlend, prevPremult := s.blend, s.premultAlpha
+	opacities := s.opacities
+	s.opacities = nil
+	s.DrawInSpace(ScreenSpace, func() {
+		for _, texIndex := range s.texGroupOrder {
+			s.SetBatchTexture(texIndex)
+			quads := s.texGroups[texIndex]
+			for i := range quads {
+				s.SetBlendMode(quads[i].blend)
+				s.SetAlphaPremultiplied(quads[i].premult)
+				s.addTexQuad(&quads[i])
+			}
+			s.texGroups[texIndex] = quads[:0]
+		}
+	})
+	s.SetBlendMode(prevBlend)
+	s.SetAlphaPremultiplied(prevPremult)
+	s.opacities = opacities
+	s.texGroupOrder = s.texGroupOrder[:0]
+}
+
+// SetBlendMode applies mode to batches flushed from now on, changing it flushes the current batch
+func (s *SystemSolution) SetBlendMode(mode BlendMode) {
+	if mode == s.blend {
+		return
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.blend = mode
+	s.lib.SetBlendMode(mode)
+}
+func (s *SystemSolution) GetBlendMode() BlendMode {
+	return s.blend
+}
+
+// SetPixelSnap rounds every batched vertex to the nearest whole pixel in screen space,
+// after the camera and transforms are applied. It is off by default.
+func (s *SystemSolution) SetPixelSnap(enabled bool) {
+	s.pixelSnap = enabled
+}
+func (s *SystemSolution) IsPixelSnapEnabled() bool {
+	return s.pixelSnap
+}
+
+// Opacity
+// PushOpacity multiplies the alpha of every vertex added until the matching PopOpacity by
+// opacity, nested pushes multiply together. With premultiplied alpha the color channels are
+// scaled as well.
+func (s *SystemSolution) PushOpacity(opacity float32) {
+	s.opacities = append(s.opacities, s.GetOpacity()*clampChannel(opacity))
+}
+func (s *SystemSolution) PopOpacity() {
+	if len(s.opacities) == 0 {
+		return
+	}
+	s.opacities = s.opacities[:len(s.opacities)-1]
+}
+
+// GetOpacity returns the combined opacity of every push, 1 with none
+func (s *SystemSolution) GetOpacity() float32 {
+	if len(s.opacities) == 0 {
+		return 1
+	}
+	return s.opacities[len(s.opacities)-1]
+}
+
+// fadeColor returns color with the current opacity applied, written to fadeScratch so neither it
+// nor color escapes to the heap. The result is only valid until the next call, which is fine for
+// the backend since it copies each vertex color as it is added.
+func (s *SystemSolution) fadeColor(color *Color) *Color {
+	s.fadeScratch = *color
+	if len(s.opacities) == 0 {
+		return &s.fadeScratch
+	}
+	opacity := s.opacities[len(s.opacities)-1]
+	s.fadeScratch[3] *= opacity
+	if s.premultAlpha {
+		s.fadeScratch[0], s.fadeScratch[1], s.fadeScratch[2] = s.fadeScratch[0]*opacity, s.fadeScratch[1]*opacity, s.fadeScratch[2]*opacity
+	}
+	return &s.fadeScratch
+}
+func (s *SystemSolution) SetAlphaPremultiplied(premult bool) {
+	if premult == s.premultAlpha {
+		return
+	}
+	s.DrawBatchIndexedTriangles2D()
+	s.premultAlpha = premult
+	s.lib.SetAlphaPremultiplied(premult)
+}
+
+// DrawPrimitiveVertexArray2D draws verts right away instead of batching them. Primitives
+// don't share the indexed triangle batch, so it is flushed first to keep draw order.
+// Verts go through the view and pixel snapping like batched vertices.
+func (s *SystemSolution) DrawPrimitiveVertexArray2D(verts []Vec2, color *Color, mode VertexMode) {
+	if len(verts) == 0 {
+		return
+	}
+	s.DrawBatchIndexedTriangles2D()
+	// Transformed into a reused buffer, like batch indexes, so immediate draws don't allocate
+	screen := s.primScratch[:0]
+	for _, v := range verts {
+		screen = append(screen, s.screenPos(v))
+	}
+	s.primScratch = screen
+	s.frameStats.DrawCalls++
+	s.frameStats.Vertices += len(screen)
+	s.lib.DrawPrimitiveVertexArray2D(screen, s.fadeColor(color), mode)
+}
+
+//func (s *SystemSolution) DrawTexturedVertexArray2D(texIndex TextureIndex, destVerts []Vec2, sourceVerts []Vec2, color *Color, mode VertexMode, blendAlpha bool) {
+//	s.lib.DrawTexturedVertexArray2D(texIndex, destVerts, sourceVerts, color, mode, blendAlpha)
+//}
+// Input Events
+func (s *SystemSolution) GetMouseButtonState(button MouseButton) InputState {
+	return s.lib.GetMouseButtonState(button)
+}
+func (s *SystemSolution) SetCallbackOnMouseWheelScroll(op func(offset Vec2)) {
+	s.onMouseScroll = op
+}
+func (s *SystemSolution) handleMouseScroll(offset Vec2) {
+	if s.scrollZoom != nil {
+		s.applyScrollZoom(offset)
+	}
+	if s.onMouseScroll != nil {
+		s.onMouseScroll(offset)
+	}
+}
+func (s *SystemSolution) GetMousePosition() Vec2 {
+	return s.lib.GetMousePosition()
+}
+func (s *SystemSolution) GetKeyboardKeyState(key KeyboardKey) InputState {
+	return s.lib.GetKeyboardKeyState(key)
+}
+func (s *SystemSolution) GetModifierState() KeyboardMod {
+	return s.lib.GetModifierState()
+}
+func (s *SystemSolution) SetCallbackOnRuneInput(op func(r rune)) {
+	s.lib.SetCallbackOnRuneInput(op)
+}
+func (s *SystemSolution) SetCallbackOnKeyPress(op func(key KeyboardKey, state InputState, mods KeyboardMod)) {
+	s.onKeyPress = op
+}
+func (s *SystemSolution) handleKeyPress(key KeyboardKey, state InputState, mods KeyboardMod) {
+	if state == Released {
+		delete(s.keysDown, key)
+	} else {
+		s.keysDown[key] = true
+	}
+	if s.onKeyPress != nil {
+		s.onKeyPress(key, state, mods)
+	}
+}
+
+// Edge detection compares input against a snapshot taken at each frame boundary of the Run
+// loop, so these are only meaningful for apps that draw their frames through Run
+func (s *SystemSolution) snapshotInput() {
+	for key := range s.keysPrev {
+		delete(s.keysPrev, key)
+	}
+	for key := range s.keysDown {
+		s.keysPrev[key] = true
+	}
+	for button := range s.mousePrev {
+		delete(s.mousePrev, button)
+	}
+	for button := range s.mouseDown {
+		s.mousePrev[button] = true
+	}
+}
+func (s *SystemSolution) WasKeyJustPressed(key KeyboardKey) bool {
+	return s.keysDown[key] && !s.keysPrev[key]
+}
+func (s *SystemSolution) WasKeyJustReleased(key KeyboardKey) bool {
+	return !s.keysDown[key] && s.keysPrev[key]
+}
+func (s *SystemSolution) WasMouseButtonJustPressed(button MouseButton) bool {
+	_, down := s.mouseDown[button]
+	return down && !s.mousePrev[button]
+}
+func (s *SystemSolution) WasMouseButtonJustReleased(button MouseButton) bool {
+	_, down := s.mouseDown[button]
+	return !down && s.mousePrev[button]
+}
+func (s *SystemSolution) SetCallbackOnMouseMove(op func(pos Vec2)) {
+	s.onMouseMove = op
+}
+func (s *SystemSolution) handleMouseMove(pos Vec2) {
+	for button := range s.mouseDown {
+		g := s.gestures[button]
+		if g == nil {
+			continue
+		}
+		if !g.dragging && pos.Sub(g.start).Len() <= s.dragDist {
+			continue
+		}
+		g.dragging = true
+		delta := pos.Sub(g.last)
+		g.last = pos
+		if s.onMouseDrag != nil {
+			s.onMouseDrag(g.start, pos, delta, button)
+		}
+	}
+	if s.onMouseMove != nil {
+		s.onMouseMove(pos)
+	}
+}
+func (s *SystemSolution) SetCursorVisible(visible bool) {
+	s.lib.SetCursorVisible(visible)
+}
+func (s *SystemSolution) SetCursorMode(mode CursorMode) {
+	s.lib.SetCursorMode(mode)
+}
+func (s *SystemSolution) SetCustomCursor(tex *Texture, hotspot Vec2) {
+	s.lib.SetCustomCursor(tex, hotspot)
+}
+func (s *SystemSolution) SetCallbackOnMouseButton(op func(button MouseButton, state InputState)) {
+	s.onMouseButton = op
+}
+func (s *SystemSolution) handleMouseButton(button MouseButton, state InputState) {
+	if state == Pressed {
+		s.mouseDown[button] = time.Now()
+	} else if state == Released {
+		delete(s.mouseDown, button)
+	}
+	if s.onMouseButton != nil {
+		s.onMouseButton(button, state)
+	}
+	s.updateGesture(button, state)
+}
+
+// Mouse Gestures
+type buttonGesture struct {
+	start     Vec2
+	last      Vec2
+	dragging  bool
+	clickTime time.Time
+	clickPos  Vec2
+}
+
+// SetCallbackOnMouseDrag fires on each move while a button is held, once the mouse has moved more
+// than the drag threshold from where it was pressed. delta is the movement since the previous call.
+func (s *SystemSolution) SetCallbackOnMouseDrag(op func(start Vec2, current Vec2, delta Vec2, button MouseButton)) {
+	s.onMouseDrag = op
+}
+
+// SetCallbackOnMouseClick fires when a button is released without having started a drag
+func (s *SystemSolution) SetCallbackOnMouseClick(op func(pos Vec2, button MouseButton)) {
+	s.onMouseClick = op
+}
+
+// SetCallbackOnDoubleClick fires after the click callback when a second click lands within the
+// double click window and drag threshold of the first
+func (s *SystemSolution) SetCallbackOnDoubleClick(op func(pos Vec2, button MouseButton)) {
+	s.onDoubleClick = op
+}
+
+// SetDragThreshold sets how far in pixels the mouse must move while held before it counts as a drag, 4 by default
+func (s *SystemSolution) SetDragThreshold(pixels float32) {
+	s.dragDist = pixels
+}
+
+// SetDoubleClickWindow sets the longest gap between two clicks that counts as a double click, 0.4 by default
+func (s *SystemSolution) SetDoubleClickWindow(seconds float32) {
+	s.clickWindow = time.Duration(seconds * float32(time.Second))
+}
+func (s *SystemSolution) updateGesture(button MouseButton, state InputState) {
+	pos := s.GetMousePosition()
+	g := s.gestures[button]
+	if g == nil {
+		g = &buttonGesture{}
+		s.gestures[button] = g
+	}
+	if state == Pressed {
+		g.start, g.last, g.dragging = pos, pos, false
+		return
+	}
+	if state != Released {
+		return
+	}
+	if g.dragging {
+		g.dragging = false
+		return
+	}
+	if s.onMouseClick != nil {
+		s.onMouseClick(pos, button)
+	}
+	now := time.Now()
+	if !g.clickTime.IsZero() && now.Sub(g.clickTime) <= s.clickWindow && pos.Sub(g.clickPos).Len() <= s.dragDist {
+		// A third click starts a new pair rather than firing again
+		g.clickTime = time.Time{}
+		if s.onDoubleClick != nil {
+			s.onDoubleClick(pos, button)
+		}
+		return
+	}
+	g.clickTime, g.clickPos = now, pos
+}
+func (s *SystemSolution) MouseButtonDownDuration(button MouseButton) float32 {
+	pressTime, down := s.mouseDown[button]
+	if !down || s.frameTime.Before(pressTime) {
+		return 0
+	}
+	return float32(s.frameTime.Sub(pressTime).Seconds())
+}
+func (s *SystemSolution) MouseButtonHeld(button MouseButton, seconds float32) bool {
+	_, down := s.mouseDown[button]
+	return down && s.MouseButtonDownDuration(button) >= seconds
+}
+func (s *SystemSolution) GetActiveTouches() []Touch {
+	return s.lib.GetActiveTouches()
+}
+func (s *SystemSolution) SetCallbackOnTouchBegin(op func(touch Touch)) {
+	s.lib.SetCallbackOnTouchBegin(op)
+}
+func (s *SystemSolution) SetCallbackOnTouchMove(op func(touch Touch)) {
+	s.lib.SetCallbackOnTouchMove(op)
+}
+func (s *SystemSolution) SetCallbackOnTouchEnd(op func(touch Touch)) {
+	s.lib.SetCallbackOnTouchEnd(op)
+}
+
+// Advanced Drawing Functions
+// Pixels, points, and raw lines are drawn immediately with DrawPrimitiveVertexArray2D and are
+// always 1px wide; use DrawLine or DrawCircle for sized shapes that batch with everything else.
+func (s *SystemSolution) DrawPixel(pos Vec2, color *Color) {
+	s.DrawPrimitiveVertexArray2D([]Vec2{pos}, color, Pixels)
+}
+func (s *SystemSolution) DrawPoints(points []Vec2, color *Color) {
+	s.DrawPrimitiveVertexArray2D(points, color, Pixels)
+}
+
+// DrawRawLines draws a hairline between each pair of points, an odd last point is ignored
+func (s *SystemSolution) DrawRawLines(points []Vec2, color *Color) {
+	s.DrawPrimitiveVertexArray2D(points[:len(points)&^1], color, Lines)
+}
+
+// Polygons and Circles
+func (s *SystemSolution) DrawRegularPolygon(pos Vec2, count float32, radius float32, color *Color, rotation float32) {
+	s.drawRegularPolygonGradient(pos, count, radius, color, color, rotation)
+}
+
+// drawRegularPolygonGradient draws the polygon's fan with centerColor at its center vertex and edgeColor on its rim
+func (s *SystemSolution) drawRegularPolygonGradient(pos Vec2, count float32, radius float32, centerColor *Color, edgeColor *Color, rotation float32) {
+	count = FFLoor(count)
+	n := int(count)
+	if n <= 0 {
+		return
+	}
+	idx := s.indexScratch(n)
+	s.reserveVertices(n + 1)
+	cen := s.AddVertexToBatch(pos, centerColor, Vec2{-1, -1})
+	ForEachPointOnCircle(count, radius, pos, rotation, func(i int, p Vec2) {
+		idx[i] = s.AddVertexToBatch(p, edgeColor, Vec2{-1, -1})
+		if i > 0 {
+			s.AddIndexesToBatch(cen, idx[i-1], idx[i])
+		}
+	})
+	s.AddIndexesToBatch(cen, idx[n-1], idx[0])
+}
+func (s *SystemSolution) DrawRegularPolygonN(pos Vec2, sides int, radius float32, color *Color, rotation float32) {
+	if sides < 3 {
+		return
+	}
+	s.drawFan(pos, PointsOnCircleN(sides, radius, pos, rotation), color, true)
+}
+func (s *SystemSolution) DrawRegularPolygonRing(pos Vec2, count float32, innerRadius float32, outerRadius float32, color *Color, rotation float32) {
+	count = FFLoor(count)
+	if count <= 0 {
+		return
+	}
+	idx := s.indexScratch(int(count) * 2)
+	s.reserveVertices(len(idx))
+	ForEachPointOnRing(count, innerRadius, outerRadius, pos, rotation, func(i int, p Vec2) {
+		idx[i] = s.AddVertexToBatch(p, color, Vec2{-1, -1})
+	})
+	for i := 0; i <= len(idx)-4; i += 2 {
+		s.AddIndexesToBatch(idx[i+0], idx[i+1], idx[i+2], idx[i+1], idx[i+3], idx[i+2])
+	}
+	s.AddIndexesToBatch(idx[len(idx)-2], idx[len(idx)-1], idx[0], idx[len(idx)-1], idx[1], idx[0])
+}
+func (s *SystemSolution) DrawCircleAutoPoints(pos Vec2, resolution float32, radius float32, color *Color) {
+	count := Circumference(radius) / resolution
+	s.DrawRegularPolygon(pos, count, radius, color, 0)
+}
+func (s *SystemSolution) DrawCircleRingAutoPoints(pos Vec2, resolution float32, innerRadius float32, outerRadius float32, color *Color) {
+	count := Circumference(outerRadius) / resolution
+	s.DrawRegularPolygonRing(pos, count, innerRadius, outerRadius, color, 0)
+}
+func (s *SystemSolution) DrawCircle(pos Vec2, radius float32, color *Color) {
+	s.DrawCircleAutoPoints(pos, 2, radius, color)
+}
+func (s *SystemSolution) DrawCircleRing(pos Vec2, innerRadius float32, outerRadius float32, color *Color) {
+	s.DrawCircleRingAutoPoints(pos, 2, innerRadius, outerRadius, color)
+}
+
+// DrawCircleRadialGradient fades from centerColor at pos to edgeColor at radius, for soft lights and vignettes
+func (s *SystemSolution) DrawCircleRadialGradient(pos Vec2, radius float32, centerColor *Color, edgeColor *Color) {
+	s.drawRegularPolygonGradient(pos, Circumference(radius)/2, radius, centerColor, edgeColor, 0)
+}
+
+// Smoothed Edges
+const aaFeather float32 = 1 // Width in pixels over which smoothed edges fade to transparent
+
+// DrawRegularPolygonSmooth draws the polygon with a feathered border ring whose alpha
+// falls from color's to zero across the edge, giving smooth edges without MSAA
+func (s *SystemSolution) DrawRegularPolygonSmooth(pos Vec2, count float32, radius float32, color *Color, rotation float32) {
+	count = FFLoor(count)
+	if count < 3 {
+		return
+	}
+	// Offset the corners further so the feather is a full width across the middle of each edge
+	half := (aaFeather / 2) / float32(math.Cos(math.Pi/float64(count)))
+	innerRadius := radius - half
+	if innerRadius < 0 {
+		innerRadius = 0
+	}
+	inner := PointsOnCircle(count, innerRadius, pos, rotation)
+	outer := PointsOnCircle(count, radius+half, pos, rotation)
+	s.drawFeatheredFan(pos, inner, outer, color)
+}
+func (s *SystemSolution) DrawCircleSmooth(pos Vec2, radius float32, color *Color) {
+	s.DrawRegularPolygonSmooth(pos, Circumference(radius)/2, radius, color, 0)
+}
+func (s *SystemSolution) drawFeatheredFan(center Vec2, inner []Vec2, outer []Vec2, color *Color) {
+	if len(inner) == 0 || len(inner) != len(outer) {
+		return
+	}
+	faded := color.WithAlpha(0)
+	n := len(inner)
+	scratch := s.indexScratch(n * 2)
+	inIdx, outIdx := scratch[:n], scratch[n:]
+	s.reserveVertices(n*2 + 1)
+	cen := s.AddVertexToBatch(center, color, Vec2{-1, -1})
+	for i := range inner {
+		inIdx[i] = s.AddVertexToBatch(inner[i], color, Vec2{-1, -1})
+		outIdx[i] = s.AddVertexToBatch(outer[i], &faded, Vec2{-1, -1})
+	}
+	for i := 0; i < n; i++ {
+		next := (i + 1) % n
+		s.AddIndexesToBatch(cen, inIdx[i], inIdx[next])
+		s.AddIndexesToBatch(inIdx[i], outIdx[i], inIdx[next], outIdx[i], outIdx[next], inIdx[next])
+	}
+}
+
+// Ellipses
+func (s *SystemSolution) DrawEllipse(pos Vec2, radiusX float32, radiusY float32, color *Color, rotation float32) {
+	count := ellipsePointCount(radiusX, radiusY)
+	s.drawFan(pos, EllipsePoints(count, radiusX, radiusY, pos, rotation), color, true)
+}
+func (s *SystemSolution) DrawEllipseOutline(pos Vec2, radiusX float32, radiusY float32, thickness float32, color *Color, rotation float32) {
+	count := ellipsePointCount(radiusX, radiusY)
+	s.DrawLineStrip(EllipsePoints(count, radiusX, radiusY, pos, rotation), thickness, color, true)
+}
+func ellipsePointCount(radiusX float32, radiusY float32) int {
+	count := int(Circumference(fmax(radiusX, radiusY)) / 2)
+	if count < 3 {
+		count = 3
+	}
+	return count
+}
+
+// Arcs and Pies
+func (s *SystemSolution) DrawPie(pos Vec2, radius float32, startAngle float32, endAngle float32, color *Color) {
+	sweep := clampSweep(startAngle, endAngle)
+	if sweep == 0 {
+		return
+	}
+	points := ArcPoints(arcSegments(radius, sweep, 2), radius, pos, startAngle, sweep)
+	s.drawFan(pos, points, color, false)
+}
+
+// DrawArc draws the ring segment between innerRadius and outerRadius. A thickness of zero or
+// less fills it; a positive thickness instead outlines its border with lines that wide.
+// Sweeps run from startAngle toward endAngle in either direction, clamped to one full turn.
+func (s *SystemSolution) DrawArc(pos Vec2, innerRadius float32, outerRadius float32, startAngle float32, endAngle float32, thickness float32, color *Color) {
+	sweep := clampSweep(startAngle, endAngle)
+	if sweep == 0 {
+		return
+	}
+	segments := arcSegments(outerRadius, sweep, 2)
+	inner := ArcPoints(segments, innerRadius, pos, startAngle, sweep)
+	outer := ArcPoints(segments, outerRadius, pos, startAngle, sweep)
+	if thickness > 0 {
+		s.drawArcOutline(inner, outer, thickness, color, float32(math.Abs(float64(sweep))) >= 2*math.Pi)
+		return
+	}
+	idx := s.indexScratch(len(inner) * 2)
+	s.reserveVertices(len(idx))
+	for i := range inner {
+		idx[i*2+0] = s.AddVertexToBatch(inner[i], color, Vec2{-1, -1})
+		idx[i*2+1] = s.AddVertexToBatch(outer[i], color, Vec2{-1, -1})
+	}
+	for i := 0; i <= len(idx)-4; i += 2 {
+		s.AddIndexesToBatch(idx[i+0], idx[i+1], idx[i+2], idx[i+1], idx[i+3], idx[i+2])
+	}
+}
+
+// drawArcOutline strokes the border of a ring segment, out along the outer edge and back along
+// the inner one. A full turn has no ends to join, so each edge is drawn as its own closed loop.
+func (s *SystemSolution) drawArcOutline(inner []Vec2, outer []Vec2, thickness float32, color *Color, fullTurn bool) {
+	if fullTurn {
+		s.DrawLineStrip(outer[:len(outer)-1], thickness, color, true)
+		s.DrawLineStrip(inner[:len(inner)-1], thickness, color, true)
+		return
+	}
+	border := outer
+	for i := len(inner) - 1; i >= 0; i-- {
+		border = append(border, inner[i])
+	}
+	s.DrawLineStrip(border, thickness, color, true)
+}
+
+// Fans and Strips
+func (s *SystemSolution) DrawTriangleFan(center Vec2, rim []Vec2, color *Color) {
+	if len(rim) < 2 {
+		return
+	}
+	s.drawFan(center, rim, color, false)
+}
+func (s *SystemSolution) DrawTriangleStrip(points []Vec2, color *Color) {
+	if len(points) < 3 {
+		return
+	}
+	idx := s.indexScratch(len(points))
+	s.reserveVertices(len(points))
+	for i := range points {
+		idx[i] = s.AddVertexToBatch(points[i], color, Vec2{-1, -1})
+		if i < 2 {
+			continue
+		}
+		if i%2 == 0 {
+			s.AddIndexesToBatch(idx[i-2], idx[i-1], idx[i])
+		} else {
+			s.AddIndexesToBatch(idx[i-1], idx[i-2], idx[i])
+		}
+	}
+}
+
+// indexScratch returns a length n slice reused by every shape draw to hold vertex indexes
+// while indexing, it is only valid until the next call
+func (s *SystemSolution) indexScratch(n int) []uint16 {
+	if cap(s.scratchIdx) < n {
+		s.scratchIdx = make([]uint16, n)
+	}
+	return s.scratchIdx[:n]
+}
+func (s *SystemSolution) drawFan(center Vec2, rim []Vec2, color *Color, closed bool) {
+	if len(rim) == 0 {
+		return
+	}
+	idx := s.indexScratch(len(rim))
+	s.reserveVertices(len(rim) + 1)
+	cen := s.AddVertexToBatch(center, color, Vec2{-1, -1})
+	for i := range rim {
+		idx[i] = s.AddVertexToBatch(rim[i], color, Vec2{-1, -1})
+		if i > 0 {
+			s.AddIndexesToBatch(cen, idx[i-1], idx[i])
+		}
+	}
+	if closed {
+		s.AddIndexesToBatch(cen, idx[len(idx)-1], idx[0])
+	}
+}
+
+// Arbitrary Polygons
+func (s *SystemSolution) DrawPolygon(points []Vec2, color *Color) {
+	tris, ok := TriangulatePolygon(points)
+	if !ok {
+		return
+	}
+	idx := s.indexScratch(len(points))
+	s.reserveVertices(len(points))
+	for i := range points {
+		idx[i] = s.AddVertexToBatch(points[i], color, Vec2{-1, -1})
+	}
+	for i := 0; i < len(tris); i += 3 {
+		s.AddIndexesToBatch(idx[tris[i]], idx[tris[i+1]], idx[tris[i+2]])
+	}
+}
+
+var (
+	ErrPolygonUVCount  = errors.New("sysgapp: textured polygon needs exactly one uv per point")
+	ErrPolygonNotDrawn = errors.New("sysgapp: polygon is not simple, has zero area, or has fewer than 3 points")
+)
+
+// DrawTexturedPolygon triangulates points like DrawPolygon and samples texIndex at the matching
+// uv of each point, in texture pixels like DrawFromTexComplete's source rect. It is not deferred
+// by texture grouping, so it keeps its order with the rest of the batch.
+func (s *SystemSolution) DrawTexturedPolygon(texIndex TextureIndex, points []Vec2, uvs []Vec2, color *Color) error {
+	if len(points) != len(uvs) {
+		return ErrPolygonUVCount
+	}
+	tris, ok := TriangulatePolygon(points)
+	if !ok {
+		return ErrPolygonNotDrawn
+	}
+	s.SetBatchTexture(s.resolveTexture(texIndex))
+	idx := s.indexScratch(len(points))
+	s.reserveVertices(len(points))
+	for i := range points {
+		idx[i] = s.AddVertexToBatch(points[i], color, uvs[i])
+	}
+	for i := 0; i < len(tris); i += 3 {
+		s.AddIndexesToBatch(idx[tris[i]], idx[tris[i+1]], idx[tris[i+2]])
+	}
+	return nil
+}
+func (s *SystemSolution) DrawPolygonOutline(points []Vec2, thickness float32, color *Color, closed bool) {
+	s.DrawLineStrip(points, thickness, color, closed)
+}
+
+// DrawPolygonFilledOutline fills the polygon then strokes its closed outline on top, centered on its edges
+func (s *SystemSolution) DrawPolygonFilledOutline(points []Vec2, fill *Color, outline *Color, thickness float32) {
+	s.DrawPolygon(points, fill)
+	s.DrawLineStrip(points, thickness, outline, true)
+}
+
+// Grids
+// DrawGrid draws lines across area every cellSize, placed so one line of each direction passes
+// through the origin of space; see DrawGridAligned to anchor them elsewhere
+func (s *SystemSolution) DrawGrid(area Rect2D, cellSize Vec2, color *Color, thickness float32, space DrawSpace) {
+	s.DrawGridAligned(area, Vec2{}, cellSize, color, thickness, space)
+}
+
+// DrawGridAligned draws lines across area every cellSize through origin, so the grid stays put
+// as area or the camera moves. area, origin and cellSize are in space, so a WorldSpace grid
+// follows the camera while a ScreenSpace one stays fixed to the window. Only lines within both
+// area and the visible part of space are drawn, however large area is.
+func (s *SystemSolution) DrawGridAligned(area Rect2D, origin Vec2, cellSize Vec2, color *Color, thickness float32, space DrawSpace) {
+	s.DrawInSpace(space, func() {
+		s.drawGridAligned(area, origin, cellSize, color, thickness)
+	})
+}
+func (s *SystemSolution) drawGridAligned(area Rect2D, origin Vec2, cellSize Vec2, color *Color, thickness float32) {
+	area, ok := s.gridArea(area, Vec2{thickness / 2, thickness / 2})
+	if !ok || cellSize.X() <= 0 || cellSize.Y() <= 0 {
+		return
+	}
+	half := thickness / 2
+	right, bottom := area.X()+area.W(), area.Y()+area.H()
+	firstX, columns := gridLines(area.X()-half, right+half, origin.X(), cellSize.X())
+	for i := 0; i < columns; i++ {
+		x := gridLine(origin.X(), cellSize.X(), firstX+float64(i))
+		s.DrawRect(Rect2D{x - half, area.Y(), thickness, area.H()}, color)
+	}
+	firstY, rows := gridLines(area.Y()-half, bottom+half, origin.Y(), cellSize.Y())
+	for i := 0; i < rows; i++ {
+		y := gridLine(origin.Y(), cellSize.Y(), firstY+float64(i))
+		s.DrawRect(Rect2D{area.X(), y - half, area.W(), thickness}, color)
+	}
+}
+
+// DrawGridDots draws a radius sized dot at every grid point inside area, aligned to the origin of space like DrawGrid
+func (s *SystemSolution) DrawGridDots(area Rect2D, spacing Vec2, color *Color, radius float32, space DrawSpace) {
+	s.DrawGridDotsAligned(area, Vec2{}, spacing, color, radius, space)
+}
+func (s *SystemSolution) DrawGridDotsAligned(area Rect2D, origin Vec2, spacing Vec2, color *Color, radius float32, space DrawSpace) {
+	s.DrawInSpace(space, func() {
+		s.drawGridDotsAligned(area, origin, spacing, color, radius)
+	})
+}
+func (s *SystemSolution) drawGridDotsAligned(area Rect2D, origin Vec2, spacing Vec2, color *Color, radius float32) {
+	visible, ok := s.gridArea(area, Vec2{radius, radius})
+	if !ok || spacing.X() <= 0 || spacing.Y() <= 0 {
+		return
+	}
+	right, bottom := area.X()+area.W(), area.Y()+area.H()
+	endX := fmin(right, visible.X()+visible.W()+radius)
+	endY := fmin(bottom, visible.Y()+visible.H()+radius)
+	firstX, columns := gridLines(fmax(area.X(), visible.X()-radius), endX, origin.X(), spacing.X())
+	firstY, rows := gridLines(fmax(area.Y(), visible.Y()-radius), endY, origin.Y(), spacing.Y())
+	for row := 0; row < rows; row++ {
+		y := gridLine(origin.Y(), spacing.Y(), firstY+float64(row))
+		for col := 0; col < columns; col++ {
+			s.DrawCircle(Vec2{gridLine(origin.X(), spacing.X(), firstX+float64(col)), y}, radius, color)
+		}
+	}
+}
+
+// gridArea trims area to the part of the draw space that is visible, grown by margin so
+// lines and dots straddling the edge are kept
+func (s *SystemSolution) gridArea(area Rect2D, margin Vec2) (Rect2D, bool) {
+	view, visible := s.visibleBounds()
+	if !visible {
+		return Rect2D{}, false
+	}
+	view = Rect2D{view.X() - margin.X(), view.Y() - margin.Y(), view.W() + margin.X()*2, view.H() + margin.Y()*2}
+	return area.Intersect(view)
+}
+
+// gridLines returns which multiple of step from origin is the first at or after from, and how many
+// fall between from and to. Lines are counted rather than stepped to in float32, which stops
+// advancing once step is below the precision of large coordinates.
+func gridLines(from float32, to float32, origin float32, step float32) (first float64, count int) {
+	first = math.Ceil((float64(from) - float64(origin)) / float64(step))
+	last := math.Floor((float64(to) - float64(origin)) / float64(step))
+	if last < first {
+		return first, 0
+	}
+	return first, int(last-first) + 1
+}
+
+// gridLine returns the position of the nth multiple of step from origin
+func gridLine(origin float32, step float32, n float64) float32 {
+	return float32(float64(origin) + n*float64(step))
+}
+
+// Stars
+func (s *SystemSolution) DrawStar(pos Vec2, points int, innerRadius float32, outerRadius float32, color *Color, rotation float32) {
+	verts := StarPoints(points, innerRadius, outerRadius, pos, rotation)
+	if len(verts) == 0 {
+		return
+	}
+	s.drawFan(pos, verts, color, true)
+}
+func (s *SystemSolution) DrawStarOutline(pos Vec2, points int, innerRadius float32, outerRadius float32, thickness float32, color *Color, rotation float32) {
+	verts := StarPoints(points, innerRadius, outerRadius, pos, rotation)
+	if len(verts) == 0 {
+		return
+	}
+	s.DrawLineStrip(verts, thickness, color, true)
+}
+
+// Rectangles
+func (s *SystemSolution) DrawRect(rect Rect2D, color *Color) {
+	s.DrawRectRotated(rect, color, 0, Vec2{})
+}
+func (s *SystemSolution) DrawRectOutline(rect Rect2D, color *Color, thickness float32) {
+	s.DrawRectOutlineRotated(rect, color, thickness, 0, Vec2{})
 }
 func (s *SystemSolution) DrawRectRotated(rect Rect2D, color *Color, rotation float32, anchor Vec2) {
 	var rectPoints [4]Vec2
@@ -344,6 +2316,12 @@ func (s *SystemSolution) DrawRectRotated(rect Rect2D, color *Color, rotation flo
 	} else {
 		rectPoints = rect.Points()
 	}
+	s.drawRectPoints(rectPoints, color)
+}
+
+// drawRectPoints fills the quad with corners tl, tr, br, bl
+func (s *SystemSolution) drawRectPoints(rectPoints [4]Vec2, color *Color) {
+	s.reserveVertices(4)
 	tl := s.AddVertexToBatch(rectPoints[0], color, Vec2{-1, -1})
 	tr := s.AddVertexToBatch(rectPoints[1], color, Vec2{-1, -1})
 	br := s.AddVertexToBatch(rectPoints[2], color, Vec2{-1, -1})
@@ -361,6 +2339,12 @@ func (s *SystemSolution) DrawRectOutlineRotated(rect Rect2D, color *Color, thick
 		rectPointsInner = rect.Points()
 		rectPointsOuter = rectOuter.Points()
 	}
+	s.drawRectOutlinePoints(rectPointsInner, rectPointsOuter, color)
+}
+
+// drawRectOutlinePoints fills the band between the inner and outer corners, each tl, tr, br, bl
+func (s *SystemSolution) drawRectOutlinePoints(rectPointsInner [4]Vec2, rectPointsOuter [4]Vec2, color *Color) {
+	s.reserveVertices(8)
 	idx := []uint16{
 		s.AddVertexToBatch(rectPointsInner[0], color, Vec2{-1, -1}),
 		s.AddVertexToBatch(rectPointsOuter[0], color, Vec2{-1, -1}),
@@ -371,20 +2355,244 @@ func (s *SystemSolution) DrawRectOutlineRotated(rect Rect2D, color *Color, thick
 		s.AddVertexToBatch(rectPointsInner[3], color, Vec2{-1, -1}),
 		s.AddVertexToBatch(rectPointsOuter[3], color, Vec2{-1, -1}),
 	}
-	s.AddIndexesToBatch(idx[0], idx[1], idx[2], idx[1], idx[3], idx[2], idx[2], idx[3], idx[4], idx[3], idx[5], idx[4], idx[4], idx[5], idx[6], idx[5], idx[7], idx[6], idx[6], idx[7], idx[0], idx[7], idx[1], idx[0])
+	s.AddIndexesToBatch(idx[0], idx[1], idx[2], idx[1], idx[3], idx[2], idx[2], idx[3], idx[4], idx[3], idx[5], idx[4], idx[4], idx[5], idx[6], idx[5], idx[7], idx[6], idx[6], idx[7], idx[0], idx[7], idx[1], idx[0])
+}
+
+// DrawRectFilledOutline fills rect then draws an outline like DrawRectOutline's on top,
+// computing the corner points once for both so the two stay exactly aligned
+func (s *SystemSolution) DrawRectFilledOutline(rect Rect2D, fill *Color, outline *Color, thickness float32) {
+	inner := rect.Points()
+	outer := rect.ExpandCopyFromCenter(Vec2{thickness, thickness}).Points()
+	s.reserveVertices(12)
+	var fillIdx [4]uint16
+	for i := range inner {
+		fillIdx[i] = s.AddVertexToBatch(inner[i], fill, Vec2{-1, -1})
+	}
+	s.AddIndexesToBatch(fillIdx[3], fillIdx[0], fillIdx[2], fillIdx[0], fillIdx[1], fillIdx[2])
+	var ringIdx [8]uint16
+	for i := range inner {
+		ringIdx[i*2+0] = s.AddVertexToBatch(inner[i], outline, Vec2{-1, -1})
+		ringIdx[i*2+1] = s.AddVertexToBatch(outer[i], outline, Vec2{-1, -1})
+	}
+	for i := 0; i < 8; i += 2 {
+		in0, out0, in1, out1 := ringIdx[i], ringIdx[i+1], ringIdx[(i+2)%8], ringIdx[(i+3)%8]
+		s.AddIndexesToBatch(in0, out0, in1, out0, out1, in1)
+	}
+}
+func (s *SystemSolution) DrawRectGradient(rect Rect2D, topLeft *Color, topRight *Color, bottomRight *Color, bottomLeft *Color) {
+	rectPoints := rect.Points()
+	s.reserveVertices(4)
+	tl := s.AddVertexToBatch(rectPoints[0], topLeft, Vec2{-1, -1})
+	tr := s.AddVertexToBatch(rectPoints[1], topRight, Vec2{-1, -1})
+	br := s.AddVertexToBatch(rectPoints[2], bottomRight, Vec2{-1, -1})
+	bl := s.AddVertexToBatch(rectPoints[3], bottomLeft, Vec2{-1, -1})
+	s.AddIndexesToBatch(bl, tl, br, tl, tr, br)
+}
+func (s *SystemSolution) DrawRectGradientVertical(rect Rect2D, top *Color, bottom *Color) {
+	s.DrawRectGradient(rect, top, top, bottom, bottom)
+}
+func (s *SystemSolution) DrawRectGradientHorizontal(rect Rect2D, left *Color, right *Color) {
+	s.DrawRectGradient(rect, left, right, right, left)
+}
+func (s *SystemSolution) DrawRectRounded(rect Rect2D, radius float32, color *Color) {
+	if radius <= 0 {
+		s.DrawRect(rect, color)
+		return
+	}
+	center := Vec2{rect.X() + rect.W()/2, rect.Y() + rect.H()/2}
+	s.drawFan(center, RoundedRectPoints(rect, radius), color, true)
+}
+func (s *SystemSolution) DrawRectRoundedOutline(rect Rect2D, radius float32, thickness float32, color *Color) {
+	if radius <= 0 {
+		s.DrawRectOutline(rect, color, thickness)
+		return
+	}
+	half := thickness / 2
+	s.DrawLineStrip(RoundedRectPoints(rect.OutsetUniform(half), radius+half), thickness, color, true)
+}
+func (s *SystemSolution) DrawRectGlow(rect Rect2D, innerColor *Color, edgeColor *Color, feather float32) {
+	inner := rect.Points()
+	outer := rect.OutsetUniform(feather).Points()
+	s.reserveVertices(8)
+	idx := []uint16{
+		s.AddVertexToBatch(inner[0], innerColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(outer[0], edgeColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(inner[1], innerColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(outer[1], edgeColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(inner[2], innerColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(outer[2], edgeColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(inner[3], innerColor, Vec2{-1, -1}),
+		s.AddVertexToBatch(outer[3], edgeColor, Vec2{-1, -1}),
+	}
+	s.AddIndexesToBatch(idx[6], idx[0], idx[4], idx[0], idx[2], idx[4])
+	s.AddIndexesToBatch(idx[0], idx[1], idx[2], idx[1], idx[3], idx[2], idx[2], idx[3], idx[4], idx[3], idx[5], idx[4], idx[4], idx[5], idx[6], idx[5], idx[7], idx[6], idx[6], idx[7], idx[0], idx[7], idx[1], idx[0])
+}
+
+// Overlays
+func (s *SystemSolution) DrawFadeOverlay(color *Color, space DrawSpace) {
+	s.DrawInSpace(space, func() {
+		s.DrawRect(NewRect2D(Vec2{}, s.GetWindowSize()), color)
+	})
+}
+
+// Lines
+func (s *SystemSolution) DrawLine(a Vec2, b Vec2, thickness float32, color *Color) {
+	l := NewLine2D(a, b)
+	l1, l2 := l.PerpLines(thickness / 2)
+	s.reserveVertices(4)
+	idx := []uint16{
+		s.AddVertexToBatch(l1.A(), color, Vec2{-1, -1}),
+		s.AddVertexToBatch(l2.A(), color, Vec2{-1, -1}),
+		s.AddVertexToBatch(l1.B(), color, Vec2{-1, -1}),
+		s.AddVertexToBatch(l2.B(), color, Vec2{-1, -1}),
+	}
+	s.AddIndexesToBatch(idx[0], idx[1], idx[2], idx[1], idx[3], idx[2])
+}
+func (s *SystemSolution) DrawLineDashed(a Vec2, b Vec2, thickness float32, color *Color, dashLength float32, gapLength float32) {
+	s.DrawLineDashedOffset(a, b, thickness, color, dashLength, gapLength, 0)
+}
+
+// DrawLineDashedOffset shifts the dash pattern back along the line by offset, advance
+// it each frame to animate marching ants. A zero length pattern draws a solid line.
+func (s *SystemSolution) DrawLineDashedOffset(a Vec2, b Vec2, thickness float32, color *Color, dashLength float32, gapLength float32, offset float32) {
+	if dashLength < 0 {
+		dashLength = 0
+	}
+	if gapLength < 0 {
+		gapLength = 0
+	}
+	period := dashLength + gapLength
+	if period <= 0 || gapLength == 0 {
+		s.DrawLine(a, b, thickness, color)
+		return
+	}
+	length := b.Sub(a).Len()
+	if length == 0 {
+		return
+	}
+	dir := b.Sub(a).Norm()
+	phase := float32(math.Mod(float64(offset), float64(period)))
+	if phase < 0 {
+		phase += period
+	}
+	for t := -phase; t < length; t += period {
+		start, end := fmax(t, 0), fmin(t+dashLength, length)
+		if end > start {
+			s.DrawLine(a.Add(dir.Mag(start)), a.Add(dir.Mag(end)), thickness, color)
+		}
+	}
+}
+
+// DrawLineCapped draws a line ending in the given cap, DrawLine is the CapButt shortcut
+func (s *SystemSolution) DrawLineCapped(a Vec2, b Vec2, thickness float32, color *Color, lineCap LineCap) {
+	half := thickness / 2
+	var dir Vec2
+	if b != a {
+		dir = b.Sub(a).Norm()
+	}
+	switch lineCap {
+	case CapSquare:
+		s.DrawLine(a.Sub(dir.Mag(half)), b.Add(dir.Mag(half)), thickness, color)
+	case CapRound:
+		s.DrawLine(a, b, thickness, color)
+		angle := float32(math.Atan2(float64(dir.Y()), float64(dir.X())))
+		segments := arcSegments(half, math.Pi, 2)
+		s.drawFan(a, ArcPoints(segments, half, a, angle+math.Pi/2, math.Pi), color, false)
+		s.drawFan(b, ArcPoints(segments, half, b, angle-math.Pi/2, math.Pi), color, false)
+	default:
+		s.DrawLine(a, b, thickness, color)
+	}
+}
+
+// SetMiterLimit sets how many half-thicknesses a mitered joint may extend before it is beveled instead
+func (s *SystemSolution) SetMiterLimit(limit float32) {
+	s.miterLimit = limit
+}
+func (s *SystemSolution) DrawLineStrip(points []Vec2, thickness float32, color *Color, closed bool) {
+	// Paths and edge directions go in reused buffers, like batch indexes, so strokes don't allocate
+	s.pathScratch = appendDedupedPath(s.pathScratch[:0], points, closed)
+	pts := s.pathScratch
+	count := len(pts)
+	if count < 2 {
+		return
+	}
+	if count == 2 {
+		closed = false
+	}
+	limit := s.miterLimit
+	if limit <= 0 {
+		limit = miterLimitDefault
+	}
+	half := thickness / 2
+	s.reserveVertices(count * 5)
+	dirs := s.dirScratch[:0]
+	for i := range pts {
+		dirs = append(dirs, pts[(i+1)%count].Sub(pts[i]).Norm())
+	}
+	s.dirScratch = dirs
+	// Each joint ends the incoming segment at inL/inR and starts the outgoing one at outL/outR
+	var firstL, firstR, prevL, prevR uint16
+	for i := range pts {
+		p := pts[i]
+		hasPrev, hasNext := i > 0 || closed, i < count-1 || closed
+		var inL, inR, outL, outR uint16
+		switch {
+		case !hasPrev:
+			_, n := dirs[i].Perp()
+			n = n.Mag(half)
+			outL = s.AddVertexToBatch(p.Add(n), color, Vec2{-1, -1})
+			outR = s.AddVertexToBatch(p.Sub(n), color, Vec2{-1, -1})
+			inL, inR = outL, outR
+		case !hasNext:
+			_, n := dirs[i-1].Perp()
+			n = n.Mag(half)
+			inL = s.AddVertexToBatch(p.Add(n), color, Vec2{-1, -1})
+			inR = s.AddVertexToBatch(p.Sub(n), color, Vec2{-1, -1})
+			outL, outR = inL, inR
+		default:
+			d0, d1 := dirs[(i-1+count)%count], dirs[i]
+			_, n0 := d0.Perp()
+			_, n1 := d1.Perp()
+			var miter Vec2
+			if sum := n0.Add(n1); sum.Len() > 0 {
+				miter = sum.Norm()
+			}
+			if cos := miter.Dot(n0); cos > 1/limit {
+				m := miter.Mag(half / cos)
+				inL = s.AddVertexToBatch(p.Add(m), color, Vec2{-1, -1})
+				inR = s.AddVertexToBatch(p.Sub(m), color, Vec2{-1, -1})
+				outL, outR = inL, inR
+				break
+			}
+			inL = s.AddVertexToBatch(p.Add(n0.Mag(half)), color, Vec2{-1, -1})
+			inR = s.AddVertexToBatch(p.Sub(n0.Mag(half)), color, Vec2{-1, -1})
+			outL = s.AddVertexToBatch(p.Add(n1.Mag(half)), color, Vec2{-1, -1})
+			outR = s.AddVertexToBatch(p.Sub(n1.Mag(half)), color, Vec2{-1, -1})
+			cen := s.AddVertexToBatch(p, color, Vec2{-1, -1})
+			if d0.Cross(d1) > 0 {
+				s.AddIndexesToBatch(cen, inR, outR)
+			} else {
+				s.AddIndexesToBatch(cen, inL, outL)
+			}
+		}
+		if i == 0 {
+			firstL, firstR = inL, inR
+		} else {
+			s.AddIndexesToBatch(prevL, prevR, inL, prevR, inR, inL)
+		}
+		prevL, prevR = outL, outR
+	}
+	if closed {
+		s.AddIndexesToBatch(prevL, prevR, firstL, prevR, firstR, firstL)
+	}
 }
 
-// Lines
-func (s *SystemSolution) DrawLine(a Vec2, b Vec2, thickness float32, color *Color) {
-	l := NewLine2D(a, b)
-	l1, l2 := l.PerpLines(thickness / 2)
-	idx := []uint16{
-		s.AddVertexToBatch(l1.A(), color, Vec2{-1, -1}),
-		s.AddVertexToBatch(l2.A(), color, Vec2{-1, -1}),
-		s.AddVertexToBatch(l1.B(), color, Vec2{-1, -1}),
-		s.AddVertexToBatch(l2.B(), color, Vec2{-1, -1}),
-	}
-	s.AddIndexesToBatch(idx[0], idx[1], idx[2], idx[1], idx[3], idx[2])
+// Curves
+func (s *SystemSolution) DrawQuadraticBezier(p0 Vec2, p1 Vec2, p2 Vec2, thickness float32, color *Color, segments int) {
+	s.DrawLineStrip(QuadraticBezierPoints(p0, p1, p2, segments), thickness, color, false)
+}
+func (s *SystemSolution) DrawCubicBezier(p0 Vec2, p1 Vec2, p2 Vec2, p3 Vec2, thickness float32, color *Color, segments int) {
+	s.DrawLineStrip(CubicBezierPoints(p0, p1, p2, p3, segments), thickness, color, false)
 }
 
 // Triangle Multi-Strips
@@ -394,12 +2602,18 @@ func (s *SystemSolution) DrawMultiTriStrips(strips TriStrips, pos Vec2, color *C
 }
 func (s *SystemSolution) DrawMultiStripsPreTranslated(strips TriStrips, color *Color) {
 	for _, strip := range strips {
-		idx := make([]uint16, len(strip))
+		idx := s.indexScratch(len(strip))
+		s.reserveVertices(len(strip))
 		for i := range strip {
 			idx[i] = s.AddVertexToBatch(strip[i], color, Vec2{-1, -1})
 		}
-		for i := 0; i <= len(idx)-4; i += 2 {
-			s.AddIndexesToBatch(idx[i+0], idx[i+1], idx[i+2], idx[i+1], idx[i+3], idx[i+2])
+		// One triangle per vertex after the second, so odd-length strips keep their last triangle
+		for i := 2; i < len(idx); i++ {
+			if i%2 == 0 {
+				s.AddIndexesToBatch(idx[i-2], idx[i-1], idx[i])
+			} else {
+				s.AddIndexesToBatch(idx[i-1], idx[i-2], idx[i])
+			}
 		}
 	}
 }
@@ -439,6 +2653,48 @@ func (s *SystemSolution) DrawFromTexTintedRotatedScaled(texIndex TextureIndex, s
 	scaledSize := Vec2{source.W() * scaleX, source.H() * scaleY}
 	s.DrawFromTexComplete(texIndex, source, NewRect2D(pos, scaledSize), color, rotation, anchor, true)
 }
+func (s *SystemSolution) DrawFromTexTiled(texIndex TextureIndex, source Rect2D, dest Rect2D, color *Color) {
+	s.DrawFromTexTiledComplete(texIndex, source, dest, color, true)
+}
+
+// DrawFromTexTiledComplete repeats source across dest from its top-left corner, clipping the
+// last column and row of tiles to fit when partialTiles is true, otherwise leaving them empty
+func (s *SystemSolution) DrawFromTexTiledComplete(texIndex TextureIndex, source Rect2D, dest Rect2D, color *Color, partialTiles bool) {
+	tileW, tileH := source.W(), source.H()
+	if tileW <= 0 || tileH <= 0 {
+		return
+	}
+	for y := float32(0); y < dest.H(); y += tileH {
+		h := tileH
+		if y+h > dest.H() {
+			if !partialTiles {
+				break
+			}
+			h = dest.H() - y
+		}
+		for x := float32(0); x < dest.W(); x += tileW {
+			w := tileW
+			if x+w > dest.W() {
+				if !partialTiles {
+					break
+				}
+				w = dest.W() - x
+			}
+			src := NewRect2D(Vec2{source.X(), source.Y()}, Vec2{w, h})
+			dst := NewRect2D(Vec2{dest.X() + x, dest.Y() + y}, Vec2{w, h})
+			s.DrawFromTexComplete(texIndex, src, dst, color, 0, Vec2{}, true)
+		}
+	}
+}
+
+// DrawFromTexPremult draws with premultiplied or straight alpha for this quad only, restoring
+// the current mode afterwards
+func (s *SystemSolution) DrawFromTexPremult(texIndex TextureIndex, source Rect2D, dest Rect2D, color *Color, rotation float32, anchor Vec2, premult bool) {
+	prevPremult := s.premultAlpha
+	s.SetAlphaPremultiplied(premult)
+	s.DrawFromTexComplete(texIndex, source, dest, color, rotation, anchor, true)
+	s.SetAlphaPremultiplied(prevPremult)
+}
 func (s *SystemSolution) DrawFromTexComplete(texIndex TextureIndex, source Rect2D, dest Rect2D, color *Color, rotation float32, anchor Vec2, blendAlpha bool) {
 	var dPoints [4]Vec2
 	if rotation != 0 {
@@ -446,64 +2702,847 @@ func (s *SystemSolution) DrawFromTexComplete(texIndex TextureIndex, source Rect2
 	} else {
 		dPoints = dest.Points()
 	}
-	sPoints := source.Points()
-	tl := s.AddVertexToBatch(dPoints[0], color, sPoints[0])
-	tr := s.AddVertexToBatch(dPoints[1], color, sPoints[1])
-	br := s.AddVertexToBatch(dPoints[2], color, sPoints[2])
-	bl := s.AddVertexToBatch(dPoints[3], color, sPoints[3])
+	blend := s.blend
+	if !blendAlpha {
+		blend = BlendNone
+	}
+	s.drawFromTexPoints(texIndex, source.Points(), dPoints, color, blend)
+}
+
+// drawFromTexPoints maps the source corners (tl, tr, br, bl) onto arbitrary dest corners,
+// switching to blend for this quad only if it differs from the current blend mode
+func (s *SystemSolution) drawFromTexPoints(texIndex TextureIndex, source [4]Vec2, dest [4]Vec2, color *Color, blend BlendMode) {
+	texIndex = s.resolveTexture(texIndex)
+	quad := texQuad{dest: dest, source: source, color: *color, blend: blend, premult: s.premultAlpha}
+	if s.texGrouping {
+		quad.color = *s.fadeColor(color)
+		for i := range quad.dest {
+			quad.dest[i] = s.spacePos(quad.dest[i])
+		}
+		if s.texGroups == nil {
+			s.texGroups = make(map[TextureIndex][]texQuad)
+		}
+		if len(s.texGroups[texIndex]) == 0 {
+			s.texGroupOrder = append(s.texGroupOrder, texIndex)
+		}
+		s.texGroups[texIndex] = append(s.texGroups[texIndex], quad)
+		return
+	}
+	prevBlend := s.blend
+	s.SetBlendMode(blend)
+	s.SetBatchTexture(texIndex)
+	s.addTexQuad(&quad)
+	s.SetBlendMode(prevBlend)
+}
+
+type texQuad struct {
+	dest    [4]Vec2
+	source  [4]Vec2
+	color   Color
+	blend   BlendMode
+	premult bool
+}
+
+func (s *SystemSolution) addTexQuad(q *texQuad) {
+	s.reserveVertices(4)
+	tl := s.AddVertexToBatch(q.dest[0], &q.color, q.source[0])
+	tr := s.AddVertexToBatch(q.dest[1], &q.color, q.source[1])
+	br := s.AddVertexToBatch(q.dest[2], &q.color, q.source[2])
+	bl := s.AddVertexToBatch(q.dest[3], &q.color, q.source[3])
 	s.AddIndexesToBatch(bl, tl, br, tl, tr, br)
 }
 
+// Nine-Patch
+// DrawNinePatch stretches source into dest keeping its borders fixed size. insets holds the
+// left (X), top (Y), right (W), and bottom (H) border widths in source pixels; borders are
+// scaled down proportionally if dest is too small to fit them.
+func (s *SystemSolution) DrawNinePatch(texIndex TextureIndex, source Rect2D, dest Rect2D, insets Rect2D, color *Color) {
+	s.drawNinePatch(texIndex, source, dest, insets, color)
+}
+func (s *SystemSolution) drawNinePatch(texIndex TextureIndex, source Rect2D, dest Rect2D, insets Rect2D, color *Color) {
+	left, top, right, bottom := insets.X(), insets.Y(), insets.W(), insets.H()
+	dLeft, dTop, dRight, dBottom := left, top, right, bottom
+	if left+right > dest.W() && left+right > 0 {
+		ratio := dest.W() / (left + right)
+		dLeft, dRight = left*ratio, right*ratio
+	}
+	if top+bottom > dest.H() && top+bottom > 0 {
+		ratio := dest.H() / (top + bottom)
+		dTop, dBottom = top*ratio, bottom*ratio
+	}
+	sx := [4]float32{source.X(), source.X() + left, source.X() + source.W() - right, source.X() + source.W()}
+	sy := [4]float32{source.Y(), source.Y() + top, source.Y() + source.H() - bottom, source.Y() + source.H()}
+	dx := [4]float32{dest.X(), dest.X() + dLeft, dest.X() + dest.W() - dRight, dest.X() + dest.W()}
+	dy := [4]float32{dest.Y(), dest.Y() + dTop, dest.Y() + dest.H() - dBottom, dest.Y() + dest.H()}
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			dSize := Vec2{dx[col+1] - dx[col], dy[row+1] - dy[row]}
+			sSize := Vec2{sx[col+1] - sx[col], sy[row+1] - sy[row]}
+			if dSize.X() <= 0 || dSize.Y() <= 0 || sSize.X() <= 0 || sSize.Y() <= 0 {
+				continue
+			}
+			src := NewRect2D(Vec2{sx[col], sy[row]}, sSize)
+			dst := NewRect2D(Vec2{dx[col], dy[row]}, dSize)
+			s.DrawFromTexComplete(texIndex, src, dst, color, 0, Vec2{}, true)
+		}
+	}
+}
+
 // Vector Text
 func (s *SystemSolution) DrawQuadVecText(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32) {
-	font := s.fonts[fontIndex]
-	x, y := pos.X(), pos.Y()
+	s.drawQuadVecText(fontIndex, text, pos, color, textSize, nil)
+}
+func (s *SystemSolution) DrawQuadVecTextFunc(fontIndex FontIndex, text string, pos Vec2, textSize float32, perGlyph func(index int, ch rune, glyphPos Vec2, strips TriStrips) (Vec2, TriStrips, *Color)) {
+	s.drawQuadVecText(fontIndex, text, pos, &ColorWhite, textSize, perGlyph)
+}
+
+// DrawQuadVecTextWithShadow draws the text in shadowColor at pos+shadowOffset, then in color at pos
+func (s *SystemSolution) DrawQuadVecTextWithShadow(fontIndex FontIndex, text string, pos Vec2, color *Color, shadowColor *Color, textSize float32, shadowOffset Vec2) {
+	s.drawQuadVecText(fontIndex, text, pos.Add(shadowOffset), shadowColor, textSize, nil)
+	s.drawQuadVecText(fontIndex, text, pos, color, textSize, nil)
+}
+
+// DrawQuadVecTextWithOutline builds the outline from copies of the text drawn in outlineColor
+// at 8 offsets outlineWidth away from pos, then draws the fill in color on top of them
+func (s *SystemSolution) DrawQuadVecTextWithOutline(fontIndex FontIndex, text string, pos Vec2, color *Color, outlineColor *Color, textSize float32, outlineWidth float32) {
+	if outlineWidth > 0 {
+		ForEachPointOnCircle(8, outlineWidth, pos, 0, func(i int, p Vec2) {
+			s.drawQuadVecText(fontIndex, text, p, outlineColor, textSize, nil)
+		})
+	}
+	s.drawQuadVecText(fontIndex, text, pos, color, textSize, nil)
+}
+
+// DrawQuadVecTextHollow draws only an outline of each glyph, outlineWidth pixels wide at any textSize.
+// Unlike the pre-built outline font this strokes the solid glyph shapes, working them out on every call.
+func (s *SystemSolution) DrawQuadVecTextHollow(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, outlineWidth float32) {
+	s.drawQuadVecText(fontIndex, text, pos, color, textSize, func(index int, ch rune, glyphPos Vec2, strips TriStrips) (Vec2, TriStrips, *Color) {
+		return glyphPos, strips.Outline(outlineWidth), nil
+	})
+}
+
+// DrawQuadVecTextRotated lays text out as DrawQuadVecText does, then rotates it by rotation radians about pos
+func (s *SystemSolution) DrawQuadVecTextRotated(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, rotation float32) {
+	if rotation == 0 {
+		s.DrawQuadVecText(fontIndex, text, pos, color, textSize)
+		return
+	}
+	prevTurn := s.textTurn
+	s.textTurn = &glyphTurn{
+		turn:     Affine2DTranslate(Vec2{-pos.X(), -pos.Y()}).Then(Affine2DRotate(rotation)).Then(Affine2DTranslate(pos)),
+		rotation: rotation,
+	}
+	s.drawQuadVecText(fontIndex, text, pos, color, textSize, s.textTurn.glyph)
+	s.textTurn = prevTurn
+}
+
+// glyphTurn rotates the glyphs of a DrawQuadVecTextRotated call about its pos, it is kept on
+// the SystemSolution while the text draws so missing glyph boxes turn with the rest of the text
+type glyphTurn struct {
+	turn     Affine2D
+	rotation float32
+}
+
+func (t *glyphTurn) glyph(index int, ch rune, glyphPos Vec2, strips TriStrips) (Vec2, TriStrips, *Color) {
+	// Rotating the glyph about its own origin and its origin about pos rotates the glyph about pos
+	return t.turn.Apply(glyphPos), strips.Rotate(t.rotation, Vec2{}), nil
+}
+func (t *glyphTurn) points(points [4]Vec2) [4]Vec2 {
+	if t != nil {
+		for i := range points {
+			points[i] = t.turn.Apply(points[i])
+		}
+	}
+	return points
+}
+func (s *SystemSolution) DrawQuadVecTextWrapped(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, maxWidth float32) (height float32) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return 0
+	}
+	font := s.textFont(fontIndex)
 	ratio := textSize / font.scale.Y()
-	runes := []rune(text)
+	wrapped := wrapQuadVecText(font, []rune(text), ratio, maxWidth)
+	s.drawQuadVecText(fontIndex, string(wrapped), pos, color, textSize, nil)
+	return layoutQuadVecText(font, wrapped, ratio, nil).Y()
+}
+
+// WrapQuadVecText returns text with line breaks inserted the same way DrawQuadVecTextWrapped
+// places them, for passing to the aligned and styled text functions
+func (s *SystemSolution) WrapQuadVecText(fontIndex FontIndex, text string, textSize float32, maxWidth float32) string {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return text
+	}
+	font := s.textFont(fontIndex)
+	return string(wrapQuadVecText(font, []rune(text), textSize/font.scale.Y(), maxWidth))
+}
+
+// wrapQuadVecText replaces spaces with line breaks wherever the next word would pass
+// maxWidth, breaking inside a word only when the word alone is wider than maxWidth
+func wrapQuadVecText(font textFont, runes []rune, ratio float32, maxWidth float32) []rune {
+	width := func(r []rune) float32 {
+		return layoutQuadVecText(font, r, ratio, nil).X()
+	}
+	out := make([]rune, 0, len(runes)+8)
+	for lineNum, line := range splitRunes(runes, '\n') {
+		if lineNum > 0 {
+			out = append(out, '\n')
+		}
+		current := make([]rune, 0, len(line))
+		for wordNum, word := range splitRunes(line, ' ') {
+			candidate := append(append([]rune{}, current...), word...)
+			if wordNum > 0 {
+				candidate = append(append(append([]rune{}, current...), ' '), word...)
+			}
+			if wordNum == 0 || width(candidate) <= maxWidth {
+				current = candidate
+			} else {
+				out = append(append(out, current...), '\n')
+				current = append(current[:0], word...)
+			}
+			for len(current) > 1 && width(current) > maxWidth {
+				split := 1
+				for split < len(current) && width(current[:split+1]) <= maxWidth {
+					split++
+				}
+				out = append(append(out, current[:split]...), '\n')
+				current = append([]rune{}, current[split:]...)
+			}
+		}
+		out = append(out, current...)
+	}
+	return out
+}
+func splitRunes(runes []rune, sep rune) [][]rune {
+	parts := make([][]rune, 0, 4)
+	start := 0
+	for i, r := range runes {
+		if r == sep {
+			parts = append(parts, runes[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, runes[start:])
+}
+func (s *SystemSolution) DrawQuadVecTextAligned(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, align TextAlign) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return
+	}
+	font := s.textFont(fontIndex)
+	ratio := textSize / font.scale.Y()
+	lineAdvance := (font.scale.Y() + font.lineSpacing) * ratio
+	for lineNum, line := range splitRunes([]rune(text), '\n') {
+		linePos := Vec2{pos.X() - alignOffset(layoutQuadVecText(font, line, ratio, nil).X(), align), pos.Y() + float32(lineNum)*lineAdvance}
+		s.drawQuadVecText(fontIndex, string(line), linePos, color, textSize, nil)
+	}
+}
+
+// DrawQuadVecTextStyled draws aligned text, then underlines and/or strikes through each line
+// from its first to its last drawn glyph, leaving leading and trailing spaces bare
+func (s *SystemSolution) DrawQuadVecTextStyled(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, align TextAlign, style TextStyle) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return
+	}
+	font := s.textFont(fontIndex)
+	ratio := textSize / font.scale.Y()
+	lineAdvance := (font.scale.Y() + font.lineSpacing) * ratio
+	thickness := fmax(textSize/14, 1)
+	for lineNum, line := range splitRunes([]rune(text), '\n') {
+		linePos := Vec2{pos.X() - alignOffset(layoutQuadVecText(font, line, ratio, nil).X(), align), pos.Y() + float32(lineNum)*lineAdvance}
+		s.drawQuadVecText(fontIndex, string(line), linePos, color, textSize, nil)
+		start, end := glyphSpan(font, line, ratio)
+		if end <= start {
+			continue
+		}
+		if style&StyleUnderline != 0 {
+			s.DrawRect(NewRect2D(Vec2{linePos.X() + start, linePos.Y() + textSize + thickness}, Vec2{end - start, thickness}), color)
+		}
+		if style&StyleStrikethrough != 0 {
+			s.DrawRect(NewRect2D(Vec2{linePos.X() + start, linePos.Y() + textSize*0.55 - thickness/2}, Vec2{end - start, thickness}), color)
+		}
+	}
+}
+
+// glyphSpan returns the horizontal extent of the glyphs drawn for a single line, excluding surrounding whitespace
+func glyphSpan(font textFont, line []rune, ratio float32) (start float32, end float32) {
+	first := true
+	layoutQuadVecText(font, line, ratio, func(idx int, glyph rune, from *QuadPolyFont, glyphRatio float32, at Vec2) {
+		width := font.scale.W() * ratio
+		if from != nil {
+			width = from.glyphs[glyph].size.W() * glyphRatio
+		}
+		if first {
+			start, first = at.X(), false
+		}
+		end = at.X() + width
+	})
+	return start, end
+}
+func alignOffset(width float32, align TextAlign) float32 {
+	switch align {
+	case AlignCenter:
+		return width / 2
+	case AlignRight:
+		return width
+	}
+	return 0
+}
+func (s *SystemSolution) MeasureQuadVecText(fontIndex FontIndex, text string, textSize float32) Vec2 {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return Vec2{}
+	}
+	font := s.textFont(fontIndex)
+	return layoutQuadVecText(font, []rune(text), textSize/font.scale.Y(), nil)
+}
+
+// textFont bundles a font with the per-font layout settings registered on the SystemSolution
+type textFont struct {
+	*QuadPolyFont
+	kerning   KerningTable
+	tabs      float32
+	fallbacks []*QuadPolyFont
+	missing   MissingGlyphMode
+}
+
+const tabWidthDefault = 4
+
+// tabWidth returns the distance between tab stops in font units
+func (f textFont) tabWidth() float32 {
+	if f.tabs > 0 {
+		return f.tabs * f.scale.W()
+	}
+	return tabWidthDefault * f.scale.W()
+}
+
+// lookup finds the font and glyph that draw c, checking the fallback chain before the
+// replacement glyph, and returns a nil font if none of them can
+func (f textFont) lookup(c rune) (*QuadPolyFont, rune) {
+	if _, ok := f.glyphs[c]; ok {
+		return f.QuadPolyFont, c
+	}
+	for _, fallback := range f.fallbacks {
+		if _, ok := fallback.glyphs[c]; ok {
+			return fallback, c
+		}
+	}
+	if _, ok := f.glyphs['�']; ok {
+		return f.QuadPolyFont, '�'
+	}
+	return nil, c
+}
+func (s *SystemSolution) textFont(fontIndex FontIndex) textFont {
+	return textFont{
+		QuadPolyFont: s.fonts[fontIndex],
+		kerning:      s.kerning[fontIndex],
+		tabs:         s.tabWidths[fontIndex],
+		fallbacks:    s.fallbackChain(fontIndex),
+		missing:      s.missingGlyphMode(fontIndex),
+	}
+}
+
+const fallbackDepthMax = 8
+
+// SetFallbackFont makes text in primary draw runes it lacks from fallback (and then fallback's own
+// fallbacks) before using a missing glyph, setting a font as its own fallback removes it
+func (s *SystemSolution) SetFallbackFont(primary FontIndex, fallback FontIndex) {
+	if primary == fallback {
+		delete(s.fallbacks, primary)
+		return
+	}
+	if s.fallbacks == nil {
+		s.fallbacks = make(map[FontIndex]FontIndex)
+	}
+	s.fallbacks[primary] = fallback
+}
+
+// fallbackChain follows fallbacks from fontIndex, stopping at fallbackDepthMax fonts or the first repeated font
+func (s *SystemSolution) fallbackChain(fontIndex FontIndex) []*QuadPolyFont {
+	var chain []*QuadPolyFont
+	seen := []FontIndex{fontIndex}
+	next, ok := s.fallbacks[fontIndex]
+	for ok && len(chain) < fallbackDepthMax {
+		for _, idx := range seen {
+			if idx == next {
+				return chain
+			}
+		}
+		if font, exists := s.fonts[next]; exists {
+			chain = append(chain, font)
+		}
+		seen = append(seen, next)
+		next, ok = s.fallbacks[next]
+	}
+	return chain
+}
+
+// SetTabWidth sets the distance between tab stops for the given font, measured in space widths from the line start
+func (s *SystemSolution) SetTabWidth(fontIndex FontIndex, spaces float32) {
+	if s.tabWidths == nil {
+		s.tabWidths = make(map[FontIndex]float32)
+	}
+	s.tabWidths[fontIndex] = spaces
+}
+
+// SetFontKerning replaces the kerning table used when laying out text in the given font, nil removes kerning
+func (s *SystemSolution) SetFontKerning(fontIndex FontIndex, kerning KerningTable) {
+	if s.kerning == nil {
+		s.kerning = make(map[FontIndex]KerningTable)
+	}
+	s.kerning[fontIndex] = kerning
+}
+
+// SetKerning adjusts the advance between a and b (in font units) when drawn consecutively in the given font
+func (s *SystemSolution) SetKerning(fontIndex FontIndex, a rune, b rune, adjust float32) {
+	if s.kerning[fontIndex] == nil {
+		s.SetFontKerning(fontIndex, make(KerningTable))
+	}
+	s.kerning[fontIndex].SetKerning(a, b, adjust)
+}
+
+// layoutQuadVecText walks runes with the spacing rules shared by every vector text
+// function, calling place (if not nil) with the offset from the text origin of each
+// visible rune, the glyph that represents it, and the font it came from scaled by
+// glyphRatio, or from == nil when neither the font, its fallbacks, nor the replacement
+// glyph has it. It returns the size of the text's bounds.
+func layoutQuadVecText(font textFont, runes []rune, ratio float32, place func(idx int, glyph rune, from *QuadPolyFont, glyphRatio float32, at Vec2)) Vec2 {
+	return walkQuadVecText(font, runes, ratio, place, nil)
+}
+
+// walkQuadVecText is layoutQuadVecText that also calls caret (if not nil) with the caret
+// offset before each rune and, with idx == len(runes), after the last one
+func walkQuadVecText(font textFont, runes []rune, ratio float32, place func(idx int, glyph rune, from *QuadPolyFont, glyphRatio float32, at Vec2), caret func(idx int, at Vec2)) Vec2 {
+	if len(runes) == 0 {
+		if caret != nil {
+			caret(0, Vec2{})
+		}
+		return Vec2{}
+	}
+	x, y := float32(0), float32(0)
+	width := float32(0)
+	lines := 1
+	prev := rune(-1)
+	trailing := float32(0)
 	for idx, c := range runes {
+		if idx > 0 {
+			prev = runes[idx-1]
+		}
+		trailing = 0
+		if caret != nil && (c == ' ' || c == '\t' || c == '\n') {
+			caret(idx, Vec2{x, y})
+		}
 		if c == ' ' {
 			x += font.scale.W() * ratio
+			if x > width {
+				width = x
+			}
+			continue
+		}
+		if c == '\t' {
+			stop := font.tabWidth() * ratio
+			if stop > 0 {
+				x = (float32(math.Floor(float64(x/stop))) + 1) * stop
+			}
+			if x > width {
+				width = x
+			}
 			continue
 		}
 		if c == '\n' {
-			x = pos.X()
+			x = 0
 			y += (font.scale.Y() + font.lineSpacing) * ratio
+			lines++
 			continue
 		}
-		char, exists := font.glyphs[c]
-		if !exists {
-			char, exists = font.glyphs['�']
-			if !exists {
-				s.DrawRect(NewRect2D(Vec2{x, y}, font.scale.Mag(ratio)), color)
-				x += font.scale.Mag(ratio).W() + (font.charSpacing * ratio)
-				continue
+		from, glyph := font.lookup(c)
+		if from == nil && font.missing == MissingGlyphSkip {
+			if caret != nil {
+				caret(idx, Vec2{x, y})
 			}
+			continue
+		}
+		glyphRatio := ratio
+		glyphWidth := font.scale.W() * ratio
+		if from != nil {
+			glyphRatio = ratio * font.scale.Y() / from.scale.Y()
+			glyphWidth = from.glyphs[glyph].size.W() * glyphRatio
+		}
+		if adjust, kerned := font.kerning[[2]rune{prev, c}]; kerned {
+			x += adjust * ratio
+		}
+		if caret != nil {
+			caret(idx, Vec2{x, y})
 		}
+		if place != nil {
+			place(idx, glyph, from, glyphRatio, Vec2{x, y})
+		}
+		if x+glyphWidth > width {
+			width = x + glyphWidth
+		}
+		trailing = font.charSpacing * ratio
+		x += glyphWidth + trailing
+	}
+	if caret != nil {
+		caret(len(runes), Vec2{x - trailing, y})
+	}
+	height := float32(lines)*font.scale.Y()*ratio + float32(lines-1)*font.lineSpacing*ratio
+	return Vec2{width, height}
+}
+
+// CaretXForIndex returns the x offset from the line start of a caret placed before rune index
+// of single line text (or after the last rune for index == rune count), clamping index to the text
+func (s *SystemSolution) CaretXForIndex(fontIndex FontIndex, text string, textSize float32, index int) float32 {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return 0
+	}
+	font := s.textFont(fontIndex)
+	caretX := float32(0)
+	walkQuadVecText(font, []rune(text), textSize/font.scale.Y(), nil, func(idx int, at Vec2) {
+		if idx <= index {
+			caretX = at.X()
+		}
+	})
+	return caretX
+}
+
+// IndexForCaretX returns the rune index of the caret boundary nearest to x in single line text
+func (s *SystemSolution) IndexForCaretX(fontIndex FontIndex, text string, textSize float32, x float32) int {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return 0
+	}
+	font := s.textFont(fontIndex)
+	best, bestDist := 0, float32(math.MaxFloat32)
+	walkQuadVecText(font, []rune(text), textSize/font.scale.Y(), nil, func(idx int, at Vec2) {
+		if dist := float32(math.Abs(float64(at.X() - x))); dist < bestDist {
+			best, bestDist = idx, dist
+		}
+	})
+	return best
+}
+func (s *SystemSolution) drawQuadVecText(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32, perGlyph func(index int, ch rune, glyphPos Vec2, strips TriStrips) (Vec2, TriStrips, *Color)) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return
+	}
+	font := s.textFont(fontIndex)
+	ratio := textSize / font.scale.Y()
+	runes := []rune(text)
+	layoutQuadVecText(font, runes, ratio, func(idx int, glyph rune, from *QuadPolyFont, glyphRatio float32, at Vec2) {
+		glyphPos := pos.Add(at)
+		if from == nil {
+			s.drawMissingGlyph(fontIndex, runes[idx], glyphPos, ratio, color)
+			return
+		}
+		char := from.glyphs[glyph]
+		c := runes[idx]
 		var cStrips TriStrips
 		if (c == '"' || c == '\'') && (idx == 0 || unicode.IsSpace(runes[idx-1])) && (idx+1 == len(runes) || unicode.IsPrint(runes[idx+1])) {
 			cStrips = char.StripsFlipX()
 		} else {
 			cStrips = char.strips
 		}
-		cStrips = cStrips.Scale(Vec2{ratio, ratio})
-		scaledWidth := char.size.W() * ratio
-		s.DrawMultiTriStrips(cStrips, Vec2{x, y}, color)
-		x += scaledWidth + (font.charSpacing * ratio)
+		cStrips = cStrips.Scale(Vec2{glyphRatio, glyphRatio})
+		glyphColor := color
+		if perGlyph != nil {
+			var newColor *Color
+			glyphPos, cStrips, newColor = perGlyph(idx, c, glyphPos, cStrips)
+			if newColor != nil {
+				glyphColor = newColor
+			}
+		}
+		s.DrawMultiTriStrips(cStrips, glyphPos, glyphColor)
+	})
+}
+
+// SetMissingGlyphMode sets the mode for fonts without their own from SetFontMissingGlyphMode
+func (s *SystemSolution) SetMissingGlyphMode(mode MissingGlyphMode) {
+	s.missingGlyph = mode
+}
+
+// SetFontMissingGlyphMode sets how runes the font, its fallback fonts, and its replacement glyph all lack are drawn
+func (s *SystemSolution) SetFontMissingGlyphMode(fontIndex FontIndex, mode MissingGlyphMode) {
+	if s.fontMissing == nil {
+		s.fontMissing = make(map[FontIndex]MissingGlyphMode)
+	}
+	s.fontMissing[fontIndex] = mode
+}
+func (s *SystemSolution) missingGlyphMode(fontIndex FontIndex) MissingGlyphMode {
+	if s.hexDigits {
+		// Digits missing from a font drawing a hex box fall back to plain boxes
+		return MissingGlyphBox
+	}
+	if mode, ok := s.fontMissing[fontIndex]; ok {
+		return mode
+	}
+	return s.missingGlyph
+}
+
+// drawMissingGlyph turns the box and hex digits with the text when drawn from DrawQuadVecTextRotated
+func (s *SystemSolution) drawMissingGlyph(fontIndex FontIndex, c rune, pos Vec2, ratio float32, color *Color) {
+	font := s.fonts[fontIndex]
+	box := NewRect2D(pos, font.scale.Mag(ratio))
+	switch s.missingGlyphMode(fontIndex) {
+	case MissingGlyphBlank, MissingGlyphSkip:
+	case MissingGlyphHex:
+		hex := fmt.Sprintf("%04X", c)
+		perRow := (len(hex) + 1) / 2
+		digitSize := box.H() * 0.4
+		outer := box.ExpandCopyFromCenter(Vec2{ratio, ratio})
+		s.drawRectOutlinePoints(s.textTurn.points(box.Points()), s.textTurn.points(outer.Points()), color)
+		var perGlyph func(index int, ch rune, glyphPos Vec2, strips TriStrips) (Vec2, TriStrips, *Color)
+		if s.textTurn != nil {
+			perGlyph = s.textTurn.glyph
+		}
+		s.hexDigits = true
+		for row := 0; row < 2; row++ {
+			digits := hex[row*perRow:]
+			if len(digits) > perRow {
+				digits = digits[:perRow]
+			}
+			digitPos := Vec2{box.X() + ratio, box.Y() + ratio + float32(row)*(box.H()/2)}
+			s.drawQuadVecText(fontIndex, digits, digitPos, color, digitSize, perGlyph)
+		}
+		s.hexDigits = false
+	default:
+		s.drawRectPoints(s.textTurn.points(box.Points()), color)
+	}
+}
+
+// Bitmap Text
+type bitmapFont struct {
+	atlas     *TextureAtlas
+	glyphs    map[rune]int
+	pixelSize float32
+	surfIndex SurfaceIndex
+}
+
+// BakeFontToAtlas draws every glyph of the font once into an atlas texture for DrawBitmapText.
+// Baking the same font again redraws into the surface it used before. Glyphs are baked in plain
+// screen pixels, ignoring the camera, transforms, design resolution, opacity and pixel snapping
+// in effect, so baking mid-frame gives the same atlas. Glyphs that don't fit the largest atlas
+// are logged and drawn as missing glyphs.
+func (s *SystemSolution) BakeFontToAtlas(fontIndex FontIndex, pixelSize int) (TextureIndex, *TextureAtlas) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return 0, nil
+	}
+	font := s.fonts[fontIndex]
+	ratio := float32(pixelSize) / font.scale.Y()
+	runes := make([]rune, 0, len(font.glyphs))
+	width := float32(0)
+	for r, char := range font.glyphs {
+		runes = append(runes, r)
+		width += char.size.W()*ratio + 1
+	}
+	atlasW := float32(256)
+	for atlasW*atlasW < width*(float32(pixelSize)+1) && atlasW < 4096 {
+		atlasW *= 2
+	}
+	atlas := NewTextureAtlas(Vec2{atlasW, atlasW})
+	glyphs := make(map[rune]int, len(runes))
+	dropped := 0
+	for _, r := range runes {
+		glyphW := float32(math.Ceil(float64(font.glyphs[r].size.W() * ratio)))
+		idx, _, err := atlas.Reserve(Vec2{glyphW, float32(pixelSize)})
+		if err != nil {
+			dropped++
+			continue
+		}
+		glyphs[r] = idx
+	}
+	if dropped > 0 {
+		log.Printf("sysgapp: %d of %d glyphs of font %d don't fit a %vx%v atlas at %d pixels, they draw as missing", dropped, len(runes), fontIndex, atlasW, atlasW, pixelSize)
+	}
+	var surfIndex SurfaceIndex
+	var texIndex TextureIndex
+	if prev, baked := s.bitmapFonts[fontIndex]; baked {
+		surfIndex, texIndex = prev.surfIndex, prev.atlas.texIndex
+	} else {
+		surfIndex, texIndex = s.nextDynamicIndex()
+	}
+	atlas.texIndex = texIndex
+	s.AddRenderSurface(surfIndex, texIndex, atlas.size)
+	s.DrawToSurface(surfIndex, func() {
+		s.ClearSurface(&Color{})
+		s.drawUntransformed(func() {
+			for r, idx := range glyphs {
+				strips := font.glyphs[r].strips.Scale(Vec2{ratio, ratio})
+				s.DrawMultiTriStrips(strips, atlas.regions[idx].Points()[0], &ColorWhite)
+			}
+		})
+		s.DrawBatchIndexedTriangles2D()
+	})
+	if s.bitmapFonts == nil {
+		s.bitmapFonts = make(map[FontIndex]*bitmapFont)
+	}
+	s.bitmapFonts[fontIndex] = &bitmapFont{
+		atlas:     atlas,
+		glyphs:    glyphs,
+		pixelSize: float32(pixelSize),
+		surfIndex: surfIndex,
+	}
+	return texIndex, atlas
+}
+
+// drawUntransformed runs op in ScreenSpace with no opacity or pixel snapping, for drawing an
+// offscreen texture that must not depend on the state of the frame being drawn around it
+func (s *SystemSolution) drawUntransformed(op func()) {
+	prevOpacities, prevSnap := s.opacities, s.pixelSnap
+	s.opacities, s.pixelSnap = nil, false
+	s.DrawInSpace(ScreenSpace, op)
+	s.opacities, s.pixelSnap = prevOpacities, prevSnap
+}
+func (s *SystemSolution) DrawBitmapText(fontIndex FontIndex, text string, pos Vec2, color *Color, textSize float32) {
+	fontIndex, found := s.resolveFont(fontIndex)
+	if !found {
+		return
+	}
+	font := s.textFont(fontIndex)
+	bFont, baked := s.bitmapFonts[fontIndex]
+	if !baked {
+		s.DrawQuadVecText(fontIndex, text, pos, color, textSize)
+		return
 	}
+	ratio := textSize / font.scale.Y()
+	bRatio := textSize / bFont.pixelSize
+	runes := []rune(text)
+	layoutQuadVecText(font, runes, ratio, func(idx int, glyph rune, from *QuadPolyFont, glyphRatio float32, at Vec2) {
+		if from != nil && from != font.QuadPolyFont {
+			// Fallback fonts aren't baked into this font's atlas, draw their vector glyphs
+			s.DrawMultiTriStrips(from.glyphs[glyph].strips.Scale(Vec2{glyphRatio, glyphRatio}), pos.Add(at), color)
+			return
+		}
+		region, inAtlas := bFont.glyphs[glyph]
+		if from == nil || !inAtlas {
+			s.drawMissingGlyph(fontIndex, runes[idx], pos.Add(at), ratio, color)
+			return
+		}
+		source := bFont.atlas.regions[region]
+		dest := NewRect2D(pos.Add(at), source.Size().Mag(bRatio))
+		s.DrawFromTexComplete(bFont.atlas.texIndex, source, dest, color, 0, Vec2{}, true)
+	})
 }
 
 // Sprite Instance
 func (s *SystemSolution) DrawSpriteInstanceTinted(sInst *SpriteInstance, pos Vec2, color *Color) {
+	s.drawSpriteFrame(sInst.GetFrame(), pos, color)
+}
+
+// DrawSpriteInstanceTransformed scales the sprite (and its draw offset) by scale, then rotates
+// it about pivot, given in normalized sprite space so {0.5, 1} is the bottom center
+func (s *SystemSolution) DrawSpriteInstanceTransformed(sInst *SpriteInstance, pos Vec2, rotation float32, pivot Vec2, scale Vec2, color *Color) {
 	frame := sInst.GetFrame()
 	source := frame.texRect
-	destPos := frame.drawOffset.Add(pos)
-	dest := NewRect2D(destPos, source.Size())
-	s.DrawFromTexComplete(frame.texIndex, source, dest, color, 0, Vec2{}, true)
+	dest := NewRect2D(frame.drawOffset.Mult(scale).Add(pos), source.Size().Mult(scale))
+	if rotation == 0 {
+		s.drawFromTexPoints(frame.texIndex, source.Points(), dest.Points(), color, s.blend)
+		return
+	}
+	pivotPos := dest.Points()[0].Add(dest.Size().Mult(pivot))
+	m := Affine2DTranslate(Vec2{-pivotPos.X(), -pivotPos.Y()}).Then(Affine2DRotate(rotation)).Then(Affine2DTranslate(pivotPos))
+	dPoints := dest.Points()
+	for i := range dPoints {
+		dPoints[i] = m.Apply(dPoints[i])
+	}
+	s.drawFromTexPoints(frame.texIndex, source.Points(), dPoints, color, s.blend)
 }
-func (s *SystemSolution) DrawSpriteInstanceDestRectTinted(sInst *SpriteInstance, dest Rect2D, color *Color) {
+
+// DrawSpriteInstanceFlipped mirrors the sprite about pos, so a draw offset that puts pos at a
+// character's feet keeps them in place when it turns to face the other way
+func (s *SystemSolution) DrawSpriteInstanceFlipped(sInst *SpriteInstance, pos Vec2, flipX bool, flipY bool, color *Color) {
 	frame := sInst.GetFrame()
 	source := frame.texRect
+	offset := frame.drawOffset
+	sPoints := source.Points()
+	if flipX {
+		offset = Vec2{-offset.X() - source.W(), offset.Y()}
+		sPoints[0], sPoints[1], sPoints[2], sPoints[3] = sPoints[1], sPoints[0], sPoints[3], sPoints[2]
+	}
+	if flipY {
+		offset = Vec2{offset.X(), -offset.Y() - source.H()}
+		sPoints[0], sPoints[1], sPoints[2], sPoints[3] = sPoints[3], sPoints[2], sPoints[1], sPoints[0]
+	}
+	dest := NewRect2D(offset.Add(pos), source.Size())
+	s.drawFromTexPoints(frame.texIndex, sPoints, dest.Points(), color, s.blend)
+}
+func (s *SystemSolution) DrawSpriteInstanceDestRectTinted(sInst *SpriteInstance, dest Rect2D, color *Color) {
+	s.drawSpriteFrameDestRect(sInst.GetFrame(), dest, color)
+}
+
+// SetSpriteFrameNinePatch gives frame a nine-patch border, laid out like DrawNinePatch's insets.
+// Borders belong to frames rather than to the draw, so each frame of an animation can move its
+// patch regions along with its artwork; frames cut from the same texture rect share one border.
+func (s *SystemSolution) SetSpriteFrameNinePatch(frame SpriteFrame, insets Rect2D) {
+	if s.frameInsets == nil {
+		s.frameInsets = make(map[SpriteFrame]Rect2D)
+	}
+	s.frameInsets[frame] = insets
+}
+
+// SpriteFrameNinePatch returns the border set for frame with SetSpriteFrameNinePatch
+func (s *SystemSolution) SpriteFrameNinePatch(frame SpriteFrame) (insets Rect2D, ok bool) {
+	insets, ok = s.frameInsets[frame]
+	return insets, ok
+}
+
+// DrawSpriteInstanceNinePatchTinted nine-patch stretches the current frame over dest using the
+// frame's own border, so an animated border keeps its corners crisp. The draw offset is scaled
+// like DrawSpriteInstanceDestRectTinted's, and a frame without a border stretches like it too.
+func (s *SystemSolution) DrawSpriteInstanceNinePatchTinted(sInst *SpriteInstance, dest Rect2D, color *Color) {
+	s.drawSpriteFrameNinePatch(sInst.GetFrame(), dest, color)
+}
+func (s *SystemSolution) drawSpriteFrameNinePatch(frame *SpriteFrame, dest Rect2D, color *Color) {
+	if frame == nil {
+		return
+	}
+	insets, ok := s.frameInsets[*frame]
+	if !ok {
+		s.drawSpriteFrameDestRect(frame, dest, color)
+		return
+	}
+	source := frame.texRect
+	scale := dest.Size().Div(source.Size())
+	s.drawNinePatch(frame.texIndex, source, dest.TranslateCopy(frame.drawOffset.Mult(scale)), insets, color)
+}
+
+// Sprite Animation
+func (s *SystemSolution) DrawSpriteAnimationTinted(anim *SpriteAnimation, pos Vec2, color *Color) {
+	s.drawSpriteFrame(anim.GetFrame(), pos, color)
+}
+func (s *SystemSolution) DrawSpriteAnimationDestRectTinted(anim *SpriteAnimation, dest Rect2D, color *Color) {
+	s.drawSpriteFrameDestRect(anim.GetFrame(), dest, color)
+}
+
+// drawSpriteFrame draws frame at its own size, shifted from pos by its draw offset. Both the
+// SpriteInstance and SpriteAnimation draws go through it; a nil frame draws nothing.
+func (s *SystemSolution) drawSpriteFrame(frame *SpriteFrame, pos Vec2, color *Color) {
+	if frame == nil {
+		return
+	}
+	source := frame.texRect
+	dest := NewRect2D(frame.drawOffset.Add(pos), source.Size())
+	s.DrawFromTexComplete(frame.texIndex, source, dest, color, 0, Vec2{}, true)
+}
+
+// drawSpriteFrameDestRect stretches frame over dest, scaling its draw offset along with it
+func (s *SystemSolution) drawSpriteFrameDestRect(frame *SpriteFrame, dest Rect2D, color *Color) {
+	if frame == nil {
+		return
+	}
+	source := frame.texRect
 	scale := dest.Size().Div(source.Size())
 	destFinal := dest.TranslateCopy(frame.drawOffset.Mult(scale))
 	s.DrawFromTexComplete(frame.texIndex, source, destFinal, color, 0, Vec2{}, true)
 }
+
+// DrawAnimationControllerTinted draws the controller's current frame like DrawSpriteAnimationTinted
+func (s *SystemSolution) DrawAnimationControllerTinted(ctrl *AnimationController, pos Vec2, color *Color) {
+	if anim, ok := ctrl.animations[ctrl.current]; ok {
+		s.DrawSpriteAnimationTinted(anim, pos, color)
+	}
+}
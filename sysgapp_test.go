@@ -0,0 +1,582 @@
+package sysgapp
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatchFlushesBeforeIndexOverflow(t *testing.T) {
+	s, lib := newFakeSolution()
+	// One rect past a full batch: 16385 rects of 4 vertices are 65540 vertices
+	rects := maxBatchVertices/4 + 1
+	for i := 0; i < rects; i++ {
+		s.DrawRect(NewRect2D(Vec2{float32(i % 800), 0}, Vec2{1, 1}), &ColorWhite)
+	}
+	s.FlushBatch()
+	if lib.flushes != 2 {
+		t.Fatalf("flushed %d batches, want 2", lib.flushes)
+	}
+	if lib.maxBatch != maxBatchVertices {
+		t.Errorf("largest batch held %d vertices, want %d", lib.maxBatch, maxBatchVertices)
+	}
+	if lib.lastBatch != 4 {
+		t.Errorf("second batch held %d vertices, want 4", lib.lastBatch)
+	}
+	if lib.badIndexes != 0 {
+		t.Errorf("%d indexes pointed past the end of their batch", lib.badIndexes)
+	}
+}
+func TestBatchPanicsOnOverflowMidPrimitive(t *testing.T) {
+	s, _ := newFakeSolution()
+	for i := 0; i < maxBatchVertices; i++ {
+		s.AddVertexToBatch(Vec2{}, &ColorWhite, Vec2{-1, -1})
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("adding a vertex to a full batch of unindexed vertices did not panic")
+		}
+	}()
+	s.AddVertexToBatch(Vec2{}, &ColorWhite, Vec2{-1, -1})
+}
+func TestRegularPolygonSmoothFeather(t *testing.T) {
+	s, lib := newFakeSolution()
+	const sides, radius = 8, 10
+	s.DrawRegularPolygonSmooth(Vec2{}, sides, radius, &ColorWhite, 0)
+	if len(lib.vertices) != sides*2+1 {
+		t.Fatalf("added %d vertices, want %d", len(lib.vertices), sides*2+1)
+	}
+	inner, outer := lib.vertices[1], lib.vertices[2]
+	innerR, outerR := inner.pos.Len(), outer.pos.Len()
+	// Across the middle of an edge the ring is a full feather wide
+	width := (outerR - innerR) * float32(math.Cos(math.Pi/sides))
+	if math.Abs(float64(width-aaFeather)) > 1e-4 {
+		t.Errorf("feather is %v wide across each edge, want %v", width, aaFeather)
+	}
+	if innerR >= radius || outerR <= radius {
+		t.Errorf("ring spans radius %v to %v, want it to straddle %v", innerR, outerR, radius)
+	}
+	if inner.color[3] != 1 || outer.color[3] != 0 {
+		t.Errorf("ring alpha fades from %v to %v, want 1 to 0", inner.color[3], outer.color[3])
+	}
+}
+func TestPolygonDrawsDoNotAllocate(t *testing.T) {
+	s, _ := newFakeSolution()
+	strips := TriStrips{{{0, 0}, {0, 1}, {1, 0}, {1, 1}}, {{2, 0}, {2, 1}, {3, 0}}}
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawRegularPolygon(Vec2{100, 100}, 64, 50, &ColorWhite, 0)
+		s.DrawMultiStripsPreTranslated(strips, &ColorWhite)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("drawing a polygon and tri strips allocated %v times per call, want 0", allocs)
+	}
+}
+func BenchmarkDrawRegularPolygon64(b *testing.B) {
+	s, _ := newFakeSolution()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.DrawRegularPolygon(Vec2{100, 100}, 64, 50, &ColorWhite, 0)
+		s.FlushBatch()
+	}
+}
+func BenchmarkDrawMultiStripsPreTranslated(b *testing.B) {
+	s, _ := newFakeSolution()
+	strips := TriStrips{{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}, {2, 1}}, {{3, 0}, {3, 1}, {4, 0}, {4, 1}}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.DrawMultiStripsPreTranslated(strips, &ColorWhite)
+		s.FlushBatch()
+	}
+}
+func TestCircleDrawsDoNotAllocate(t *testing.T) {
+	s, _ := newFakeSolution()
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawCircle(Vec2{100, 100}, 20, &ColorWhite)
+		s.DrawCircleRing(Vec2{100, 100}, 10, 20, &ColorWhite)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("drawing a circle and ring allocated %v times per call, want 0", allocs)
+	}
+}
+func BenchmarkDrawCircle(b *testing.B) {
+	s, _ := newFakeSolution()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.DrawCircle(Vec2{100, 100}, 20, &ColorWhite)
+		s.FlushBatch()
+	}
+}
+
+// Run with -race, the detector catches draws that bypass the lock
+func TestDrawLockedFromGoroutines(t *testing.T) {
+	s, lib := newFakeSolution()
+	const workers, rects = 8, 100
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < rects; i++ {
+				s.DrawLocked(func() {
+					s.DrawRect(NewRect2D(Vec2{float32(w), float32(i)}, Vec2{1, 1}), &ColorWhite)
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+	if n := lib.BatchVertexCount(); n != workers*rects*4 {
+		t.Errorf("batch holds %d vertices, want %d", n, workers*rects*4)
+	}
+	// Each rect's six indexes must refer to its own four vertices
+	for i := 0; i+6 <= len(lib.indexes); i += 6 {
+		first := uint32(i / 6 * 4)
+		for _, idx := range lib.indexes[i : i+6] {
+			if idx < first || idx >= first+4 {
+				t.Fatalf("rect %d indexes vertex %d, outside its own vertices %d to %d", i/6, idx, first, first+3)
+			}
+		}
+	}
+}
+func TestObtainLockReleasesOnPanic(t *testing.T) {
+	s, _ := newFakeSolution()
+	func() {
+		defer func() { recover() }()
+		s.DrawLocked(func() { panic("draw failed") })
+	}()
+	done := make(chan struct{})
+	go func() {
+		s.ObtainLock(func() {})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lock still held after a panicking draw")
+	}
+}
+func TestRotatedTextTurnsMissingGlyphs(t *testing.T) {
+	for _, mode := range []MissingGlyphMode{MissingGlyphBox, MissingGlyphHex} {
+		s, lib := newFakeSolution()
+		s.fonts = map[FontIndex]*QuadPolyFont{0: {scale: Vec2{10, 20}, glyphs: map[rune]*QuadGlyph{}}}
+		s.SetMissingGlyphMode(mode)
+		pos, rotation := Vec2{30, 40}, float32(math.Pi/2)
+		s.DrawQuadVecText(0, "x", pos, &ColorWhite, 20)
+		flat := append([]fakeVertex{}, lib.vertices...)
+		s.FlushBatch()
+		s.DrawQuadVecTextRotated(0, "x", pos, &ColorWhite, 20, rotation)
+		if len(flat) == 0 || len(lib.vertices) != len(flat) {
+			t.Fatalf("mode %d: drew %d vertices rotated and %d unrotated", mode, len(lib.vertices), len(flat))
+		}
+		turn := Affine2DTranslate(Vec2{-pos.X(), -pos.Y()}).Then(Affine2DRotate(rotation)).Then(Affine2DTranslate(pos))
+		for i, v := range lib.vertices {
+			if want := turn.Apply(flat[i].pos); v.pos.Sub(want).Len() > 1e-3 {
+				t.Errorf("mode %d: vertex %d at %v, want %v", mode, i, v.pos, want)
+			}
+		}
+	}
+}
+func TestOddTriStripKeepsLastTriangle(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.DrawMultiStripsPreTranslated(TriStrips{{{0, 0}, {0, 1}, {1, 0}, {1, 1}, {2, 0}}}, &ColorWhite)
+	if tris := len(lib.indexes) / 3; tris != 3 {
+		t.Errorf("5 vertex strip drew %d triangles, want 3", tris)
+	}
+}
+func TestNestedDrawToSurfaceRestoresOuterTarget(t *testing.T) {
+	s, lib := newFakeSolution()
+	check := func(when string, want drawTarget) {
+		t.Helper()
+		surfIndex, toScreen, _ := s.CurrentDrawTarget()
+		if got := (drawTarget{surfIndex: surfIndex, screen: toScreen}); got != want {
+			t.Errorf("%s: current target is %+v, want %+v", when, got, want)
+		}
+		if lib.bound != want {
+			t.Errorf("%s: backend has %+v bound, want %+v", when, lib.bound, want)
+		}
+	}
+	s.DrawToScreen(func() {
+		s.DrawToSurface(1, func() {
+			s.DrawToSurface(2, func() {
+				check("inside surface 2", drawTarget{surfIndex: 2})
+			})
+			check("after surface 2", drawTarget{surfIndex: 1})
+		})
+		check("after surface 1", drawTarget{screen: true})
+	})
+	if _, _, ok := s.CurrentDrawTarget(); ok {
+		t.Error("a draw target is still current after the outermost DrawToScreen returned")
+	}
+}
+func TestWideIndexDrawsDoNotAllocate(t *testing.T) {
+	s, _ := newFakeSolution()
+	s.SetIndexWidth(32)
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawRegularPolygon(Vec2{100, 100}, 64, 50, &ColorWhite, 0)
+		a := s.AddVertexToBatch32(Vec2{0, 0}, &ColorWhite, Vec2{-1, -1})
+		b := s.AddVertexToBatch32(Vec2{1, 0}, &ColorWhite, Vec2{-1, -1})
+		c := s.AddVertexToBatch32(Vec2{0, 1}, &ColorWhite, Vec2{-1, -1})
+		s.AddIndexesToBatch32(a, b, c)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("drawing a polygon and a triangle with 32-bit indexes allocated %v times per call, want 0", allocs)
+	}
+}
+func TestFadedDrawsDoNotAllocate(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.PushOpacity(0.5)
+	color := Color{1, 0, 0, 1}
+	s.DrawRegularPolygon(Vec2{100, 100}, 64, 50, &color, 0)
+	if a := lib.vertices[0].color[3]; a != 0.5 {
+		t.Errorf("vertex alpha is %v under PushOpacity(0.5), want 0.5", a)
+	}
+	s.FlushBatch()
+	allocs := testing.AllocsPerRun(100, func() {
+		// Colors made per draw must stay on the stack too
+		tinted := color.WithAlpha(0.8)
+		s.DrawRegularPolygon(Vec2{100, 100}, 64, 50, &tinted, 0)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("drawing a polygon with an opacity pushed allocated %v times per call, want 0", allocs)
+	}
+}
+func TestDrawGridLineCount(t *testing.T) {
+	s, lib := newFakeSolution()
+	// Lines at 0, 10, ... 100 in each direction
+	s.DrawGrid(NewRect2D(Vec2{0, 0}, Vec2{100, 100}), Vec2{10, 10}, &ColorWhite, 1, ScreenSpace)
+	if rects := lib.BatchVertexCount() / 4; rects != 22 {
+		t.Errorf("drew %d grid lines, want 22", rects)
+	}
+	s.FlushBatch()
+	// Steps of 0.1 drift when summed in float32, counting them must still reach the last line
+	s.DrawGrid(NewRect2D(Vec2{0, 0}, Vec2{100, 1}), Vec2{0.1, 10}, &ColorWhite, 0.01, ScreenSpace)
+	if rects := lib.BatchVertexCount() / 4; rects != 1001+1 {
+		t.Errorf("drew %d grid lines, want %d", rects, 1001+1)
+	}
+}
+func TestDrawGridFarFromOrigin(t *testing.T) {
+	s, lib := newFakeSolution()
+	// At 1e8 a float32 can't represent a step of 1, so adding the cell size never advances
+	far := Vec2{1e8, 1e8}
+	s.SetCamera(far, 1, 0)
+	area := NewRect2D(far, Vec2{200, 100})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.DrawGrid(area, Vec2{1, 1}, &ColorWhite, 1, WorldSpace)
+		s.DrawGridDots(area, Vec2{1, 1}, &ColorWhite, 1, WorldSpace)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("drawing a grid far from the origin did not finish")
+	}
+	if lib.BatchVertexCount() == 0 && lib.flushes == 0 {
+		t.Error("drawing a grid far from the origin drew nothing")
+	}
+}
+func TestBatchValidation(t *testing.T) {
+	triangle := func(s *SystemSolution) (a, b, c uint16) {
+		a = s.AddVertexToBatch(Vec2{0, 0}, &ColorWhite, Vec2{-1, -1})
+		b = s.AddVertexToBatch(Vec2{1, 0}, &ColorWhite, Vec2{-1, -1})
+		c = s.AddVertexToBatch(Vec2{0, 1}, &ColorWhite, Vec2{-1, -1})
+		return a, b, c
+	}
+	tests := []struct {
+		name string
+		draw func(s *SystemSolution)
+		want []error
+	}{
+		{"valid draws", func(s *SystemSolution) {
+			s.DrawRect(NewRect2D(Vec2{0, 0}, Vec2{10, 10}), &ColorWhite)
+			s.DrawCircle(Vec2{50, 50}, 20, &ColorWhite)
+			s.FlushBatch()
+		}, nil},
+		{"index out of range", func(s *SystemSolution) {
+			a, b, _ := triangle(s)
+			s.AddIndexesToBatch(a, b, 3)
+		}, []error{ErrBatchIndexRange}},
+		{"partial triangle", func(s *SystemSolution) {
+			a, b, _ := triangle(s)
+			s.AddIndexesToBatch(a, b)
+			s.FlushBatch()
+		}, []error{ErrBatchIndexCount, ErrBatchUnindexed}},
+		{"flush mid primitive", func(s *SystemSolution) {
+			s.AddIndexesToBatch(triangle(s))
+			a, b, c := triangle(s)
+			s.FlushBatch()
+			s.AddVertexToBatch(Vec2{}, &ColorWhite, Vec2{-1, -1})
+			s.AddIndexesToBatch(a, b, c)
+		}, []error{ErrBatchUnindexed, ErrBatchStaleIndex, ErrBatchStaleIndex, ErrBatchStaleIndex}},
+		{"stale only until the next flush", func(s *SystemSolution) {
+			s.AddIndexesToBatch(triangle(s))
+			triangle(s)
+			s.FlushBatch()
+			s.FlushBatch()
+			s.AddIndexesToBatch(3, 4, 5)
+		}, []error{ErrBatchUnindexed, ErrBatchIndexRange, ErrBatchIndexRange, ErrBatchIndexRange}},
+		{"wide indexes out of range", func(s *SystemSolution) {
+			s.SetIndexWidth(32)
+			a := s.AddVertexToBatch32(Vec2{}, &ColorWhite, Vec2{-1, -1})
+			s.AddIndexesToBatch32(a, a+1, a+2)
+		}, []error{ErrBatchIndexRange, ErrBatchIndexRange}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newFakeSolution()
+			var got []error
+			s.SetBatchErrorHandler(func(err error) { got = append(got, err) })
+			s.SetBatchValidation(true)
+			tt.draw(s)
+			if len(got) != len(tt.want) {
+				t.Fatalf("reported %v, want %v", got, tt.want)
+			}
+			for i, err := range got {
+				if !errors.Is(err, tt.want[i]) {
+					t.Errorf("error %d is %v, want %v", i, err, tt.want[i])
+				}
+			}
+		})
+	}
+}
+func TestStreamedTextureLoaderReturningNil(t *testing.T) {
+	s, _ := newFakeSolution()
+	var got error
+	done := false
+	s.AddTextureStreamed(5, func() (*Texture, error) { return nil, nil }, 1, func(texIndex TextureIndex, err error) {
+		done, got = true, err
+	})
+	deadline := time.Now().Add(5 * time.Second)
+	for !done {
+		if time.Now().After(deadline) {
+			t.Fatal("streamed texture never finished loading")
+		}
+		time.Sleep(time.Millisecond)
+		s.uploadStreamedTextures()
+	}
+	if !errors.Is(got, ErrNoStreamedTexture) {
+		t.Errorf("onDone got %v, want ErrNoStreamedTexture", got)
+	}
+	if tex := s.resolveTexture(5); tex != 1 {
+		t.Errorf("texture 5 draws as %d after a failed load, want the placeholder 1", tex)
+	}
+}
+func TestArcSweeps(t *testing.T) {
+	center := Vec2{100, 100}
+	angles := func(lib *fakeBackend) (lo, hi float64) {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for _, v := range lib.vertices {
+			d := v.pos.Sub(center)
+			if d.Len() < 1e-3 {
+				continue
+			}
+			a := math.Atan2(float64(d.Y()), float64(d.X()))
+			lo, hi = math.Min(lo, a), math.Max(hi, a)
+		}
+		return lo, hi
+	}
+	tests := []struct {
+		name       string
+		start, end float32
+		lo, hi     float64 // Range of vertex angles, for sweeps short of a full turn
+	}{
+		{"positive", 0, math.Pi / 2, 0, math.Pi / 2},
+		{"negative", 0, -math.Pi / 2, -math.Pi / 2, 0},
+		{"negative from a positive start", math.Pi / 2, 0, 0, math.Pi / 2},
+	}
+	for _, tt := range tests {
+		for _, draw := range []struct {
+			name string
+			op   func(s *SystemSolution)
+		}{
+			{"pie", func(s *SystemSolution) { s.DrawPie(center, 20, tt.start, tt.end, &ColorWhite) }},
+			{"arc", func(s *SystemSolution) { s.DrawArc(center, 10, 20, tt.start, tt.end, 0, &ColorWhite) }},
+		} {
+			s, lib := newFakeSolution()
+			draw.op(s)
+			if len(lib.vertices) == 0 {
+				t.Errorf("%s %s: drew nothing", tt.name, draw.name)
+				continue
+			}
+			if lo, hi := angles(lib); math.Abs(lo-tt.lo) > 1e-3 || math.Abs(hi-tt.hi) > 1e-3 {
+				t.Errorf("%s %s: vertices span angles %v to %v, want %v to %v", tt.name, draw.name, lo, hi, tt.lo, tt.hi)
+			}
+		}
+	}
+	// Sweeps past a full turn in either direction clamp to exactly one turn
+	full, _ := newFakeSolution()
+	full.DrawArc(center, 10, 20, 0, 2*math.Pi, 0, &ColorWhite)
+	for _, end := range []float32{3 * math.Pi, -3 * math.Pi, 100} {
+		s, lib := newFakeSolution()
+		s.DrawArc(center, 10, 20, 0, end, 0, &ColorWhite)
+		if n, want := len(lib.vertices), full.BatchVertexCount(); n != want {
+			t.Errorf("sweep to %v drew %d vertices, want the %d of a full turn", end, n, want)
+		}
+	}
+}
+func TestArcThicknessOutlines(t *testing.T) {
+	center := Vec2{100, 100}
+	for _, end := range []float32{math.Pi, 2 * math.Pi} {
+		s, lib := newFakeSolution()
+		s.DrawArc(center, 10, 20, 0, end, 2, &ColorWhite)
+		if len(lib.vertices) == 0 {
+			t.Fatalf("outlined arc to %v drew nothing", end)
+		}
+		for _, v := range lib.vertices {
+			// Strokes stay within the thickness of the border, leaving room for mitered corners
+			r := v.pos.Sub(center).Len()
+			if r < 10-2 || r > 20+2 {
+				t.Fatalf("outlined arc to %v has a vertex at radius %v, outside the border strokes", end, r)
+			}
+			if end == 2*math.Pi && r > 12 && r < 18 {
+				t.Errorf("outlined full ring has a vertex at radius %v, between its two edges", r)
+			}
+		}
+	}
+}
+func TestDrawGridSpace(t *testing.T) {
+	cell := Vec2{100, 100}
+	area := NewRect2D(Vec2{0, 0}, Vec2{400, 400})
+	for _, tt := range []struct {
+		space DrawSpace
+		first float32 // Left edge of the first vertical line
+	}{
+		{ScreenSpace, -0.5},
+		// The camera hides the line at 0, leaving the one at 100 first, 30 to the left on screen
+		{WorldSpace, 100 - 30 - 0.5},
+	} {
+		s, lib := newFakeSolution()
+		s.SetCamera(Vec2{30, 0}, 1, 0)
+		prev := s.space
+		s.DrawGrid(area, cell, &ColorWhite, 1, tt.space)
+		if len(lib.vertices) == 0 {
+			t.Fatalf("space %d: drew nothing", tt.space)
+		}
+		if x := lib.vertices[0].pos.X(); math.Abs(float64(x-tt.first)) > 1e-3 {
+			t.Errorf("space %d: first line starts at x %v, want %v", tt.space, x, tt.first)
+		}
+		if s.space != prev {
+			t.Errorf("space %d: drawing the grid left the draw space at %d, want %d", tt.space, s.space, prev)
+		}
+	}
+}
+func TestDrawCallWarningCountsOnlyDrawCalls(t *testing.T) {
+	var logged bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&logged)
+	frame := func(s *SystemSolution) {
+		s.Run(func() {
+			s.DrawRect(NewRect2D(Vec2{}, Vec2{1, 1}), &ColorWhite)
+			s.FlushBatch()
+			// Empty flushes and texture switches with nothing drawn aren't draw calls
+			for i := 0; i < 5; i++ {
+				s.FlushBatch()
+			}
+			s.SetBatchTexture(1)
+			s.SetBatchTexture(2)
+			s.DrawRect(NewRect2D(Vec2{}, Vec2{1, 1}), &ColorWhite)
+			s.FlushBatch()
+		})
+	}
+	s, _ := newFakeSolution()
+	s.SetDrawCallWarningThreshold(2)
+	frame(s)
+	if s.LastFrameStats().DrawCalls != 2 || logged.Len() != 0 {
+		t.Errorf("%d draw calls under a threshold of 2 logged %q", s.LastFrameStats().DrawCalls, logged.String())
+	}
+	s.SetDrawCallWarningThreshold(1)
+	frame(s)
+	if logged.Len() == 0 {
+		t.Error("2 draw calls over a threshold of 1 logged nothing")
+	}
+}
+func TestPrimitiveDrawsDoNotAllocate(t *testing.T) {
+	s, lib := newFakeSolution()
+	points := []Vec2{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawPixel(Vec2{5, 5}, &ColorWhite)
+		s.DrawPoints(points, &ColorWhite)
+		s.DrawRawLines(points, &ColorWhite)
+	})
+	if allocs != 0 {
+		t.Errorf("drawing pixels, points and raw lines allocated %v times per call, want 0", allocs)
+	}
+	if lib.primitives == 0 {
+		t.Error("no primitives reached the backend")
+	}
+}
+func TestLineStripsDoNotAllocate(t *testing.T) {
+	s, lib := newFakeSolution()
+	path := []Vec2{{0, 0}, {10, 0}, {10, 0}, {10, 10}, {0, 10}, {0, 0}}
+	allocs := testing.AllocsPerRun(100, func() {
+		s.DrawLineStrip(path, 2, &ColorWhite, false)
+		s.DrawPolygonOutline(path, 2, &ColorWhite, true)
+		s.FlushBatch()
+	})
+	if allocs != 0 {
+		t.Errorf("stroking paths allocated %v times per call, want 0", allocs)
+	}
+	s.DrawPolygonOutline(path, 2, &ColorWhite, true)
+	for _, v := range lib.vertices {
+		if v.pos != v.pos {
+			t.Fatalf("duplicate points produced a NaN vertex %v", v.pos)
+		}
+	}
+}
+func TestNinePatchAtSourceSizeMatchesPlainDraw(t *testing.T) {
+	s, lib := newFakeSolution()
+	s.texSizes[1] = Vec2{64, 64}
+	source := NewRect2D(Vec2{8, 16}, Vec2{24, 20})
+	s.DrawFromTexSourceDestRect(1, source, source)
+	plain := append([]fakeVertex{}, lib.vertices...)
+	s.FlushBatch()
+	s.DrawNinePatch(1, source, source, Rect2D{4, 5, 6, 3}, &ColorWhite)
+	patch := lib.vertices
+	if len(plain) != 4 || len(patch) != 9*4 {
+		t.Fatalf("drew %d plain and %d nine-patch vertices, want 4 and 36", len(plain), len(patch))
+	}
+	// The plain quad maps position to uv linearly, every nine-patch vertex must land on that mapping
+	lo, hi := plain[0], plain[0]
+	for _, v := range plain {
+		if v.pos.X() <= lo.pos.X() && v.pos.Y() <= lo.pos.Y() {
+			lo = v
+		}
+		if v.pos.X() >= hi.pos.X() && v.pos.Y() >= hi.pos.Y() {
+			hi = v
+		}
+	}
+	if lo.uv == hi.uv {
+		t.Fatalf("plain quad samples a single uv %v", lo.uv)
+	}
+	uvAt := func(p Vec2) Vec2 {
+		fx := (p.X() - lo.pos.X()) / (hi.pos.X() - lo.pos.X())
+		fy := (p.Y() - lo.pos.Y()) / (hi.pos.Y() - lo.pos.Y())
+		return Vec2{lo.uv.X() + fx*(hi.uv.X()-lo.uv.X()), lo.uv.Y() + fy*(hi.uv.Y()-lo.uv.Y())}
+	}
+	var area float32
+	for q := 0; q < len(patch); q += 4 {
+		qlo, qhi := patch[q].pos, patch[q].pos
+		for _, v := range patch[q : q+4] {
+			if want := uvAt(v.pos); v.uv.Sub(want).Len() > 1e-6 {
+				t.Errorf("vertex at %v samples uv %v, the plain draw samples %v there", v.pos, v.uv, want)
+			}
+			if v.pos.X() < lo.pos.X() || v.pos.Y() < lo.pos.Y() || v.pos.X() > hi.pos.X() || v.pos.Y() > hi.pos.Y() {
+				t.Errorf("vertex at %v lies outside the plain quad", v.pos)
+			}
+			qlo = Vec2{fmin(qlo.X(), v.pos.X()), fmin(qlo.Y(), v.pos.Y())}
+			qhi = Vec2{fmax(qhi.X(), v.pos.X()), fmax(qhi.Y(), v.pos.Y())}
+		}
+		area += (qhi.X() - qlo.X()) * (qhi.Y() - qlo.Y())
+	}
+	// Inside the plain quad and covering its area, so the patches tile it without gaps or overlap
+	if want := source.W() * source.H(); math.Abs(float64(area-want)) > 1e-3 {
+		t.Errorf("patches cover %v square pixels, want %v", area, want)
+	}
+}
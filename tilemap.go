@@ -0,0 +1,96 @@
+package sysgapp
+
+import (
+	"log"
+	"math"
+)
+
+// TILE MAPS
+// DrawTileMap draws tiles as a grid columns wide with its top left corner at origin. Each tile
+// value picks a tileSize cell of texIndex, counting across then down from its top left, and
+// negative values are left empty. Rows and columns outside the window (or the clip rect, if one
+// is pushed) are skipped without being visited, so large maps cost only what is on screen.
+// All tiles go straight into one batch, bypassing texture grouping.
+func (s *SystemSolution) DrawTileMap(texIndex TextureIndex, tileSize Vec2, columns int, tiles []int, origin Vec2, color *Color) {
+	if columns <= 0 || len(tiles) == 0 || tileSize.X() <= 0 || tileSize.Y() <= 0 {
+		return
+	}
+	texSize, sized := s.TextureSize(texIndex)
+	atlasColumns := int(texSize.X() / tileSize.X())
+	if !sized || atlasColumns <= 0 {
+		log.Printf("sysgapp: tile map texture %d has no known size holding a %vx%v tile, skipping", texIndex, tileSize.X(), tileSize.Y())
+		return
+	}
+	rows := (len(tiles) + columns - 1) / columns
+	view, visible := s.visibleBounds()
+	if !visible {
+		return
+	}
+	firstCol, lastCol, ok := visibleTiles(view.X()-origin.X(), view.W(), tileSize.X(), columns)
+	if !ok {
+		return
+	}
+	firstRow, lastRow, ok := visibleTiles(view.Y()-origin.Y(), view.H(), tileSize.Y(), rows)
+	if !ok {
+		return
+	}
+	s.SetBatchTexture(s.resolveTexture(texIndex))
+	quad := texQuad{color: *color, blend: s.blend}
+	for row := firstRow; row <= lastRow; row++ {
+		for col := firstCol; col <= lastCol; col++ {
+			i := row*columns + col
+			if i >= len(tiles) || tiles[i] < 0 {
+				continue
+			}
+			tile := tiles[i]
+			source := NewRect2D(Vec2{float32(tile%atlasColumns) * tileSize.X(), float32(tile/atlasColumns) * tileSize.Y()}, tileSize)
+			dest := NewRect2D(Vec2{origin.X() + float32(col)*tileSize.X(), origin.Y() + float32(row)*tileSize.Y()}, tileSize)
+			quad.source, quad.dest = source.Points(), dest.Points()
+			s.addTexQuad(&quad)
+		}
+	}
+}
+
+// visibleTiles returns the range of count tiles of size overlapping the span from start to start+length
+func visibleTiles(start float32, length float32, size float32, count int) (first int, last int, ok bool) {
+	first = int(math.Floor(float64(start / size)))
+	last = int(math.Ceil(float64((start+length)/size))) - 1
+	if last < 0 || first >= count {
+		return 0, 0, false
+	}
+	if first < 0 {
+		first = 0
+	}
+	if last >= count {
+		last = count - 1
+	}
+	return first, last, true
+}
+
+// visibleBounds returns the area of the current draw space that lands inside the window and
+// clip rect, or false if none of it does
+func (s *SystemSolution) visibleBounds() (Rect2D, bool) {
+	screen := NewRect2D(Vec2{}, s.GetWindowSize())
+	if clip, clipped := s.GetClipRect(); clipped {
+		var visible bool
+		if screen, visible = screen.Intersect(clip); !visible {
+			return Rect2D{}, false
+		}
+	}
+	toSpace := Affine2DIdentity
+	switch {
+	case s.space == WorldSpace && s.viewActive:
+		toSpace = s.view.Inverse()
+	case s.space == DesignSpace && s.designActive:
+		toSpace = s.design.Inverse()
+	}
+	corners := screen.Points()
+	first := toSpace.Apply(corners[0])
+	minX, minY, maxX, maxY := first.X(), first.Y(), first.X(), first.Y()
+	for _, c := range corners[1:] {
+		p := toSpace.Apply(c)
+		minX, minY = fmin(minX, p.X()), fmin(minY, p.Y())
+		maxX, maxY = fmax(maxX, p.X()), fmax(maxY, p.Y())
+	}
+	return NewRect2D(Vec2{minX, minY}, Vec2{maxX - minX, maxY - minY}), true
+}
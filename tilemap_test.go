@@ -0,0 +1,35 @@
+package sysgapp
+
+import "testing"
+
+func newTileMapSolution(columns int, rows int) (*SystemSolution, *fakeBackend, []int) {
+	s, lib := newFakeSolution()
+	s.texSizes[1] = Vec2{256, 256}
+	tiles := make([]int, columns*rows)
+	for i := range tiles {
+		tiles[i] = i % 256
+	}
+	return s, lib, tiles
+}
+func TestDrawTileMapSkipsOffscreenTiles(t *testing.T) {
+	s, lib, tiles := newTileMapSolution(100, 100)
+	tiles[0] = -1
+	s.DrawTileMap(1, Vec2{16, 16}, 100, tiles, Vec2{}, &ColorWhite)
+	// An 800x600 window shows 50 columns and 38 rows of 16 pixel tiles, less the empty first tile
+	if want := (50*38 - 1) * 4; lib.BatchVertexCount() != want {
+		t.Errorf("drew %d vertices, want %d", lib.BatchVertexCount(), want)
+	}
+	lib.DrawBatchIndexedTriangles2D()
+	s.DrawTileMap(1, Vec2{16, 16}, 100, tiles, Vec2{-5000, 0}, &ColorWhite)
+	if n := lib.BatchVertexCount(); n != 0 {
+		t.Errorf("a map entirely off screen drew %d vertices", n)
+	}
+}
+func BenchmarkDrawTileMap100x100(b *testing.B) {
+	s, _, tiles := newTileMapSolution(100, 100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		s.DrawTileMap(1, Vec2{16, 16}, 100, tiles, Vec2{}, &ColorWhite)
+		s.FlushBatch()
+	}
+}
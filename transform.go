@@ -0,0 +1,48 @@
+package sysgapp
+
+import "math"
+
+// AFFINE TRANSFORM
+// Affine2D maps (x, y) to (m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5])
+type Affine2D [6]float32
+
+var Affine2DIdentity = Affine2D{1, 0, 0, 1, 0, 0}
+
+func Affine2DTranslate(v Vec2) Affine2D {
+	return Affine2D{1, 0, 0, 1, v.X(), v.Y()}
+}
+func Affine2DScale(v Vec2) Affine2D {
+	return Affine2D{v.X(), 0, 0, v.Y(), 0, 0}
+}
+
+// Affine2DRotate rotates by radians, clockwise on screen where y points down
+func Affine2DRotate(radians float32) Affine2D {
+	sin, cos := math.Sincos(float64(radians))
+	return Affine2D{float32(cos), float32(sin), float32(-sin), float32(cos), 0, 0}
+}
+
+// Then returns the transform that applies m first and next afterwards
+func (m Affine2D) Then(next Affine2D) Affine2D {
+	return Affine2D{
+		next[0]*m[0] + next[2]*m[1],
+		next[1]*m[0] + next[3]*m[1],
+		next[0]*m[2] + next[2]*m[3],
+		next[1]*m[2] + next[3]*m[3],
+		next[0]*m[4] + next[2]*m[5] + next[4],
+		next[1]*m[4] + next[3]*m[5] + next[5],
+	}
+}
+func (m Affine2D) Apply(p Vec2) Vec2 {
+	return Vec2{m[0]*p.X() + m[2]*p.Y() + m[4], m[1]*p.X() + m[3]*p.Y() + m[5]}
+}
+
+// Inverse returns the inverse transform, or the identity if m is not invertible
+func (m Affine2D) Inverse() Affine2D {
+	det := m[0]*m[3] - m[1]*m[2]
+	if det == 0 {
+		return Affine2DIdentity
+	}
+	inv := 1 / det
+	a, b, c, d := m[3]*inv, -m[1]*inv, -m[2]*inv, m[0]*inv
+	return Affine2D{a, b, c, d, -(a*m[4] + c*m[5]), -(b*m[4] + d*m[5])}
+}
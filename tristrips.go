@@ -0,0 +1,118 @@
+package sysgapp
+
+import "math"
+
+// Bounds returns the smallest rect containing every strip vertex, or a zero rect if there are none
+func (t TriStrips) Bounds() Rect2D {
+	first := true
+	var minX, minY, maxX, maxY float32
+	for _, strip := range t {
+		for _, p := range strip {
+			if first {
+				minX, minY, maxX, maxY = p.X(), p.Y(), p.X(), p.Y()
+				first = false
+				continue
+			}
+			minX, minY = fmin(minX, p.X()), fmin(minY, p.Y())
+			maxX, maxY = fmax(maxX, p.X()), fmax(maxY, p.Y())
+		}
+	}
+	if first {
+		return Rect2D{}
+	}
+	return NewRect2D(Vec2{minX, minY}, Vec2{maxX - minX, maxY - minY})
+}
+
+// Rotate returns a copy of the strips rotated by angle radians about pivot, clockwise on
+// screen like Affine2DRotate. All copied vertices share one allocation.
+func (t TriStrips) Rotate(angle float32, pivot Vec2) TriStrips {
+	count := 0
+	for _, strip := range t {
+		count += len(strip)
+	}
+	backing := make([]Vec2, count)
+	rotated := make(TriStrips, len(t))
+	sin, cos := math.Sincos(float64(angle))
+	sn, cs := float32(sin), float32(cos)
+	for i, strip := range t {
+		out := backing[:len(strip):len(strip)]
+		backing = backing[len(strip):]
+		for j, p := range strip {
+			x, y := p.X()-pivot.X(), p.Y()-pivot.Y()
+			out[j] = Vec2{pivot.X() + x*cs - y*sn, pivot.Y() + x*sn + y*cs}
+		}
+		rotated[i] = out
+	}
+	return rotated
+}
+
+// Outline returns strips stroking the border of the area the strips fill, thickness wide and
+// centered on it. Triangle edges shared by two triangles are interior and dropped, as are edges
+// whose midpoint lies inside a triangle of another strip, where overlapping strokes meet. Each
+// border edge becomes its own quad extended by half the thickness at both ends to close corners.
+func (t TriStrips) Outline(thickness float32) TriStrips {
+	type triangle [3]Vec2
+	type edgeKey [4]int32
+	quantize := func(v float32) int32 {
+		return int32(math.Round(float64(v) * 1024))
+	}
+	keyOf := func(a Vec2, b Vec2) edgeKey {
+		ka, kb := [2]int32{quantize(a.X()), quantize(a.Y())}, [2]int32{quantize(b.X()), quantize(b.Y())}
+		if ka[0] > kb[0] || (ka[0] == kb[0] && ka[1] > kb[1]) {
+			ka, kb = kb, ka
+		}
+		return edgeKey{ka[0], ka[1], kb[0], kb[1]}
+	}
+	var tris []triangle
+	var owners []int
+	for si, strip := range t {
+		for i := 0; i+2 < len(strip); i++ {
+			tri := triangle{strip[i], strip[i+1], strip[i+2]}
+			area := tri[1].Sub(tri[0]).Cross(tri[2].Sub(tri[0]))
+			if area == 0 {
+				continue // Degenerate triangles only join strips
+			}
+			if area < 0 {
+				tri[1], tri[2] = tri[2], tri[1]
+			}
+			tris = append(tris, tri)
+			owners = append(owners, si)
+		}
+	}
+	counts := make(map[edgeKey]int)
+	for _, tri := range tris {
+		for e := 0; e < 3; e++ {
+			counts[keyOf(tri[e], tri[(e+1)%3])]++
+		}
+	}
+	half := thickness / 2
+	var outline TriStrips
+	for ti, tri := range tris {
+		for e := 0; e < 3; e++ {
+			a, b := tri[e], tri[(e+1)%3]
+			if counts[keyOf(a, b)] != 1 {
+				continue
+			}
+			mid := Vec2{(a.X() + b.X()) / 2, (a.Y() + b.Y()) / 2}
+			covered := false
+			for oi, other := range tris {
+				if owners[oi] == owners[ti] {
+					continue
+				}
+				if pointInsideTriangle(mid, other[0], other[1], other[2]) {
+					covered = true
+					break
+				}
+			}
+			if covered {
+				continue
+			}
+			dir := b.Sub(a).Norm()
+			along := Vec2{dir.X() * half, dir.Y() * half}
+			_, side := along.Perp()
+			start, end := a.Sub(along), b.Add(along)
+			outline = append(outline, TriStrip{start.Add(side), start.Sub(side), end.Add(side), end.Sub(side)})
+		}
+	}
+	return outline
+}
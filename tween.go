@@ -0,0 +1,178 @@
+package sysgapp
+
+import "math"
+
+// EASING
+// EaseFunc maps progress t in [0, 1] to eased progress, 0 at t = 0 and 1 at t = 1
+type EaseFunc func(t float32) float32
+
+func EaseLinear(t float32) float32 {
+	return t
+}
+func EaseInQuad(t float32) float32 {
+	return t * t
+}
+func EaseOutQuad(t float32) float32 {
+	return 1 - (1-t)*(1-t)
+}
+func EaseInOutQuad(t float32) float32 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - 2*(1-t)*(1-t)
+}
+func EaseInCubic(t float32) float32 {
+	return t * t * t
+}
+func EaseOutCubic(t float32) float32 {
+	u := 1 - t
+	return 1 - u*u*u
+}
+func EaseInOutCubic(t float32) float32 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := 1 - t
+	return 1 - 4*u*u*u
+}
+
+// EaseOutBounce settles on 1 with three shrinking bounces
+func EaseOutBounce(t float32) float32 {
+	const n, d = 7.5625, 2.75
+	switch {
+	case t < 1/d:
+		return n * t * t
+	case t < 2/d:
+		t -= 1.5 / d
+		return n*t*t + 0.75
+	case t < 2.5/d:
+		t -= 2.25 / d
+		return n*t*t + 0.9375
+	}
+	t -= 2.625 / d
+	return n*t*t + 0.984375
+}
+func EaseInBounce(t float32) float32 {
+	return 1 - EaseOutBounce(1-t)
+}
+
+// EaseOutElastic overshoots 1 and springs back to it in decaying oscillations
+func EaseOutElastic(t float32) float32 {
+	if t <= 0 || t >= 1 {
+		return t
+	}
+	return float32(math.Pow(2, -10*float64(t))*math.Sin((float64(t)*10-0.75)*(2*math.Pi/3))) + 1
+}
+func EaseInElastic(t float32) float32 {
+	return 1 - EaseOutElastic(1-t)
+}
+func LerpVec2(a Vec2, b Vec2, t float32) Vec2 {
+	return Vec2{a.X() + (b.X()-a.X())*t, a.Y() + (b.Y()-a.Y())*t}
+}
+
+// TWEENS
+type TweenMode uint8
+
+const (
+	TweenOnce     TweenMode = iota // Stops at the end value
+	TweenLoop                      // Jumps back to the start value and repeats
+	TweenPingPong                  // Reverses direction at each end and repeats
+) // Tween Modes
+
+type Tween struct {
+	from     float32
+	to       float32
+	duration float32
+	ease     EaseFunc
+	mode     TweenMode
+	elapsed  float32
+	reverse  bool
+	done     bool
+}
+
+// NewTween eases from from to to over duration seconds, a nil ease is linear
+func NewTween(from float32, to float32, duration float32, ease EaseFunc) *Tween {
+	if ease == nil {
+		ease = EaseLinear
+	}
+	return &Tween{from: from, to: to, duration: duration, ease: ease}
+}
+
+// SetMode sets whether the tween stops, loops, or ping-pongs when it reaches the end, TweenOnce by default
+func (t *Tween) SetMode(mode TweenMode) *Tween {
+	t.mode = mode
+	return t
+}
+
+// Update advances the tween by dt seconds, typically DeltaTime(), and returns its value
+func (t *Tween) Update(dt float32) float32 {
+	if t.done {
+		return t.Value()
+	}
+	if t.duration <= 0 {
+		t.elapsed, t.done = 0, true
+		return t.Value()
+	}
+	t.elapsed += dt
+	for t.elapsed >= t.duration {
+		switch t.mode {
+		case TweenLoop:
+			t.elapsed -= t.duration
+		case TweenPingPong:
+			t.elapsed -= t.duration
+			t.reverse = !t.reverse
+		default:
+			t.elapsed, t.done = t.duration, true
+			return t.Value()
+		}
+	}
+	return t.Value()
+}
+
+// Progress returns the uneased fraction of the current pass, counting down on a ping-pong's way back
+func (t *Tween) Progress() float32 {
+	if t.duration <= 0 {
+		return 1
+	}
+	p := t.elapsed / t.duration
+	if t.reverse {
+		return 1 - p
+	}
+	return p
+}
+func (t *Tween) Value() float32 {
+	return t.from + (t.to-t.from)*t.ease(t.Progress())
+}
+
+// IsDone reports whether a TweenOnce tween has reached its end value, looping tweens never finish
+func (t *Tween) IsDone() bool {
+	return t.done
+}
+func (t *Tween) Reset() {
+	t.elapsed = 0
+	t.reverse = false
+	t.done = false
+}
+
+// Vec2Tween eases a position, or any pair of values, with one shared Tween
+type Vec2Tween struct {
+	*Tween
+	fromVec Vec2
+	toVec   Vec2
+}
+
+func NewVec2Tween(from Vec2, to Vec2, duration float32, ease EaseFunc) *Vec2Tween {
+	return &Vec2Tween{Tween: NewTween(0, 1, duration, ease), fromVec: from, toVec: to}
+}
+func (t *Vec2Tween) Update(dt float32) Vec2 {
+	return LerpVec2(t.fromVec, t.toVec, t.Tween.Update(dt))
+}
+func (t *Vec2Tween) Value() Vec2 {
+	return LerpVec2(t.fromVec, t.toVec, t.Tween.Value())
+}
+
+// SetMode shadows Tween.SetMode to keep the *Vec2Tween for chaining
+func (t *Vec2Tween) SetMode(mode TweenMode) *Vec2Tween {
+	t.Tween.SetMode(mode)
+	return t
+}